@@ -0,0 +1,82 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	
+	"github.com/spf13/cobra"
+)
+
+//go:embed templates/*.tmpl
+var builtinFS embed.FS
+
+var builtinTemplates = []struct {
+	name string
+	desc string
+}{
+	{name: "github-release", desc: "GitHub release notes grouped by features, bug fixes and other changes"},
+	{name: "json", desc: "the entire template context as JSON"},
+	{name: "keepachangelog", desc: "CHANGELOG.md in the keep a changelog format with a section per release"},
+	{name: "plain", desc: "plain text list of commits per release"},
+}
+
+func builtinTemplate(name string) (string, error) {
+	b, err := builtinFS.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		names := make([]string, 0, len(builtinTemplates))
+		for _, t := range builtinTemplates {
+			names = append(names, t.name)
+		}
+		return "", fmt.Errorf("unknown builtin template %q; must be one of %s", name, strings.Join(names, ", "))
+	}
+	return string(b), nil
+}
+
+func newTemplatesCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "templates",
+		Short: "list and show the builtin templates",
+	}
+	cmd.AddCommand(
+		newTemplatesListCmd(),
+		newTemplatesShowCmd(),
+	)
+	return &cmd
+}
+
+func newTemplatesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "list the builtin templates",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			for _, t := range builtinTemplates {
+				fmt.Fprintf(w, "%s\t%s\n", t.name, t.desc)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newTemplatesShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "show NAME",
+		Short:        "print the builtin template to copy and customise",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tmpl, err := builtinTemplate(args[0])
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), tmpl)
+			return err
+		},
+		Example: `  # copy the keep a changelog template to customise it
+> gitempl templates show keepachangelog > CHANGELOG.tmpl`,
+	}
+}
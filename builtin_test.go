@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat(api): first", map[string]string{"a.txt": "a"})
+	tr.tag("v0.1.0", tr.commit("fix: second", map[string]string{"a.txt": "aa"}), "")
+	tr.commit("feat!: third", map[string]string{"b.txt": "b"})
+	tr.commit("not conventional", nil)
+	
+	render := func(t *testing.T, args ...string) string {
+		t.Helper()
+		
+		cmd := newCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs(append([]string{"--dir", tr.dir}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		return buf.String()
+	}
+	
+	t.Run("keepachangelog", func(t *testing.T) {
+		got := render(t, "--builtin", "keepachangelog")
+		
+		for _, want := range []string{
+			"- [Unreleased](#unreleased)\n- [0.1.0](#010---2024-01-01)\n",
+			"## [Unreleased]\n\n### Breaking Changes\n\n- Third (",
+			"### Added\n\n- Third (",
+			"## [0.1.0] - 2024-01-01\n\n### Added\n\n- **api:** First (",
+			"### Fixed\n\n- Second (",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("missing expected output:\n\twant: %q\n\tgot:\n%s", want, got)
+			}
+		}
+	})
+	
+	t.Run("github-release", func(t *testing.T) {
+		got := render(t, "--builtin", "github-release", "--from", "v0.1.0")
+		
+		for _, want := range []string{
+			"### ⚠ Breaking Changes\n\n* third (",
+			"### Features\n\n* third by Jane Doe in ",
+			"### Other Changes\n\n* not conventional by Jane Doe in ",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("missing expected output:\n\twant: %q\n\tgot:\n%s", want, got)
+			}
+		}
+		if strings.Contains(got, "first") {
+			t.Errorf("unexpected commit outside of range:\n%s", got)
+		}
+	})
+	
+	t.Run("plain", func(t *testing.T) {
+		got := render(t, "--builtin", "plain")
+		
+		lines := strings.Split(got, "\n")
+		mustLen(t, lines, 8)
+		if lines[0] != "Unreleased" || lines[4] != "v0.1.0 (2024-01-01)" {
+			t.Errorf("unexpected output:\n%s", got)
+		}
+	})
+	
+	t.Run("json", func(t *testing.T) {
+		var got struct {
+			Commits []struct {
+				Hash string
+			}
+		}
+		if err := json.Unmarshal([]byte(render(t, "--builtin", "json")), &got); err != nil {
+			t.Fatal(err.Error())
+		}
+		mustLen(t, got.Commits, 4)
+	})
+	
+	t.Run("only json should need stats", func(t *testing.T) {
+		for _, b := range builtinTemplates {
+			text, err := builtinTemplate(b.name)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			tmpl, err := template.New(b.name).Funcs(funcMap).Funcs((*forge)(nil).funcs()).Funcs(statsFuncs(nil, nil)).Parse(text)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got, want := templateNeedsStats(tmpl), b.name == "json"; got != want {
+				t.Errorf("templateNeedsStats(%s) = %t, want %t", b.name, got, want)
+			}
+		}
+	})
+	
+	t.Run("with unknown builtin should error", func(t *testing.T) {
+		cmd := newCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--dir", tr.dir, "--builtin", "rando"})
+		if err := cmd.Execute(); err == nil {
+			t.Fatal("expected error for unknown builtin")
+		}
+	})
+	
+	t.Run("with template and builtin should error", func(t *testing.T) {
+		cmd := newCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--dir", tr.dir, "--builtin", "plain", "--template", "foo.tmpl"})
+		if err := cmd.Execute(); err == nil {
+			t.Fatal("expected error for mutually exclusive flags")
+		}
+	})
+}
+
+func TestTemplatesCmd(t *testing.T) {
+	t.Run("list should print all builtin templates", func(t *testing.T) {
+		cmd := newCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"templates", "list"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		mustLen(t, lines, len(builtinTemplates))
+		for i, tmpl := range builtinTemplates {
+			if !strings.HasPrefix(lines[i], tmpl.name+" ") {
+				t.Errorf("unexpected line %d: %s", i, lines[i])
+			}
+		}
+	})
+	
+	t.Run("show should print the template", func(t *testing.T) {
+		cmd := newCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"templates", "show", "json"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		if want := "{{ json . }}\n"; buf.String() != want {
+			t.Errorf("unexpected output:\n\twant: %q\n\tgot: %q", want, buf.String())
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// cacheSchemaVersion is the version of the cached commit data. It must be
+// bumped whenever the parsed commit data changes, so that stale caches are
+// ignored instead of decoded.
+const cacheSchemaVersion = 6
+
+// cacheDir is the directory of the cache, relative to the .git dir.
+const cacheDir = "gitempl-cache"
+
+// commitCache is an on-disk cache of the parsed commits keyed by hash. Only
+// the data derived from the commit object itself is cached, the data
+// derived from the config, i.e. the type names, references, mailmap and
+// signature verification, is applied after.
+type commitCache struct {
+	path    string
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+type cacheEntry struct {
+	Commit commit
+	// HasFiles is true when the files of the commit were computed.
+	HasFiles bool
+}
+
+// openCommitCache reads the cache of the repo. A repo without a .git dir has
+// no cache, and a cache that fails to decode is discarded and rebuilt.
+func openCommitCache(r *git.Repository) *commitCache {
+	s, ok := r.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil
+	}
+	
+	c := &commitCache{
+		path:    filepath.Join(s.Filesystem().Root(), cacheDir, fmt.Sprintf("commits-v%d.gob", cacheSchemaVersion)),
+		entries: make(map[string]cacheEntry),
+	}
+	
+	f, err := os.Open(c.path)
+	if err != nil {
+		return c
+	}
+	defer f.Close()
+	
+	if err := gob.NewDecoder(f).Decode(&c.entries); err != nil {
+		c.entries = make(map[string]cacheEntry)
+	}
+	return c
+}
+
+// get returns the cached commit and whether its files were computed.
+func (c *commitCache) get(hash string) (commit, bool, bool) {
+	if c == nil {
+		return commit{}, false, false
+	}
+	e, ok := c.entries[hash]
+	return e.Commit, e.HasFiles, ok
+}
+
+func (c *commitCache) put(com commit, hasFiles bool) {
+	if c == nil {
+		return
+	}
+	if e, ok := c.entries[com.Hash]; ok && (e.HasFiles || !hasFiles) {
+		return
+	}
+	com.CC.TypeName = ""
+	com.PullRequest, com.Issues = nil, nil
+	com.Signature.Status, com.Signature.Signer = "", ""
+	c.entries[com.Hash] = cacheEntry{Commit: com, HasFiles: hasFiles}
+	c.dirty = true
+}
+
+// save writes the cache when any commit was added to it.
+func (c *commitCache) save() error {
+	if c == nil || !c.dirty {
+		return nil
+	}
+	
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	
+	f, err := os.CreateTemp(filepath.Dir(c.path), "commits-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	
+	if err := gob.NewEncoder(f).Encode(c.entries); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), c.path); err != nil {
+		return err
+	}
+	
+	// the caches of the other schema versions are never read again
+	stale, err := filepath.Glob(filepath.Join(filepath.Dir(c.path), "commits-v*.gob"))
+	if err != nil {
+		return err
+	}
+	for _, file := range stale {
+		if file != c.path {
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommitCache(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", map[string]string{"a.txt": "a"})
+	second := tr.commit("fix: second", map[string]string{"a.txt": "aa\nb"})
+	
+	parse := func(t *testing.T, opts parseOpts) []commit {
+		t.Helper()
+		
+		opts.cache = openCommitCache(tr.repo)
+		commits, err := parseGitTemplVars(tr.repo, opts)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := opts.cache.save(); err != nil {
+			t.Fatal(err.Error())
+		}
+		return commits
+	}
+	
+	t.Run("should cache parsed commits", func(t *testing.T) {
+		want := parse(t, parseOpts{})
+		
+		cache := openCommitCache(tr.repo)
+		if len(cache.entries) != 2 {
+			t.Fatalf("unexpected cached commits: %d", len(cache.entries))
+		}
+		got, hasFiles, ok := cache.get(second.String())
+		if !ok {
+			t.Fatal("expected cached commit")
+		}
+		if hasFiles {
+			t.Error("expected commit without files")
+		}
+		commitEq(t, want[1], got)
+	})
+	
+	t.Run("should read commits from cache", func(t *testing.T) {
+		cache := openCommitCache(tr.repo)
+		e := cache.entries[second.String()]
+		e.Commit.CC.Desc = "from cache"
+		cache.entries[second.String()] = e
+		cache.dirty = true
+		if err := cache.save(); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		got := parse(t, parseOpts{typeNames: map[string]string{"fix": "Bug Fixes"}})
+		mustLen(t, got, 2)
+		if got[1].CC.Desc != "from cache" {
+			t.Errorf("expected cached commit: %#v", got[1].CC)
+		}
+		if got[1].CC.TypeName != "Bug Fixes" {
+			t.Errorf("unexpected type name: %s", got[1].CC.TypeName)
+		}
+	})
+	
+	t.Run("should add files to cached commits", func(t *testing.T) {
+		got := parse(t, parseOpts{stats: true})
+		if got[1].TotalAdditions != 2 || got[1].TotalDeletions != 1 {
+			t.Errorf("unexpected totals: +%d -%d", got[1].TotalAdditions, got[1].TotalDeletions)
+		}
+		
+		cached, hasFiles, _ := openCommitCache(tr.repo).get(second.String())
+		if !hasFiles {
+			t.Fatal("expected cached files")
+		}
+		filesEq(t, got[1].Files, cached.Files)
+	})
+	
+	t.Run("with corrupt cache should rebuild", func(t *testing.T) {
+		cache := openCommitCache(tr.repo)
+		if err := os.WriteFile(cache.path, []byte("corrupt"), 0o644); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		if n := len(openCommitCache(tr.repo).entries); n != 0 {
+			t.Fatalf("unexpected cached commits: %d", n)
+		}
+		mustLen(t, parse(t, parseOpts{}), 2)
+		if n := len(openCommitCache(tr.repo).entries); n != 2 {
+			t.Fatalf("unexpected cached commits: %d", n)
+		}
+	})
+	
+	t.Run("should remove caches of other schema versions", func(t *testing.T) {
+		cache := openCommitCache(tr.repo)
+		stale := filepath.Join(filepath.Dir(cache.path), "commits-v1.gob")
+		if err := os.WriteFile(stale, []byte("stale"), 0o644); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		cache.dirty = true
+		if err := cache.save(); err != nil {
+			t.Fatal(err.Error())
+		}
+		if _, err := os.Stat(stale); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected stale cache to be removed: %v", err)
+		}
+		if _, err := os.Stat(cache.path); err != nil {
+			t.Errorf("expected current cache: %v", err)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// exitCodeStale is the exit code of --check when the output file is stale.
+const exitCodeStale = 3
+
+// exitError is an error that exits the process with a specific code.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
+}
+
+// checkFile compares the rendered output with the content of the file and
+// writes a unified diff of the differences to w. A missing file is compared
+// as an empty file.
+func checkFile(w io.Writer, file, rendered string) error {
+	if file == "" {
+		return errors.New("an output file is required with --check")
+	}
+	
+	b, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read file to check: %w", err)
+	}
+	
+	current := string(b)
+	if current == rendered {
+		return nil
+	}
+	
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s (rendered)\n", file, file); err != nil {
+		return err
+	}
+	patch := newTextPatch(current, rendered)
+	if err := fdiff.NewUnifiedEncoder(w, fdiff.DefaultContextLines).Encode(patch); err != nil {
+		return err
+	}
+	
+	return &exitError{
+		code: exitCodeStale,
+		msg:  fmt.Sprintf("%s is stale; regenerate it with gitempl", file),
+	}
+}
+
+// textPatch is a single file patch of the line differences between two texts.
+// It implements both fdiff.Patch and fdiff.FilePatch, without file metadata,
+// so that only the hunks are encoded.
+type textPatch struct {
+	chunks []fdiff.Chunk
+}
+
+func newTextPatch(from, to string) textPatch {
+	var p textPatch
+	for _, d := range diff.Do(from, to) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		p.chunks = append(p.chunks, textChunk{content: d.Text, op: op})
+	}
+	return p
+}
+
+func (p textPatch) FilePatches() []fdiff.FilePatch          { return []fdiff.FilePatch{p} }
+func (p textPatch) Message() string                         { return "" }
+func (p textPatch) IsBinary() bool                          { return false }
+func (p textPatch) Files() (from fdiff.File, to fdiff.File) { return nil, nil }
+func (p textPatch) Chunks() []fdiff.Chunk                   { return p.chunks }
+
+type textChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c textChunk) Content() string       { return c.content }
+func (c textChunk) Type() fdiff.Operation { return c.op }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdCheck(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", nil)
+	tr.commit("fix: second", nil)
+	
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "changelog.tmpl")
+	writeFile(t, tmpl, `# Changes
+{{ range .Commits }}* {{ .CC.Desc }}
+{{ end }}`)
+	
+	check := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		
+		return renderTemplate(t, tr.dir, "", append([]string{"-t", tmpl, "--check"}, args...)...)
+	}
+	
+	t.Run("with up to date file should pass", func(t *testing.T) {
+		out := filepath.Join(dir, "up-to-date.md")
+		writeFile(t, out, "# Changes\n* first\n* second\n")
+		
+		got, err := check(t, out)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if got != "" {
+			t.Errorf("unexpected output: %q", got)
+		}
+	})
+	
+	t.Run("with stale file should print diff and fail", func(t *testing.T) {
+		out := filepath.Join(dir, "stale.md")
+		writeFile(t, out, "# Changes\n* first\n")
+		
+		got, err := check(t, out)
+		var exitErr *exitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("expected exit error, got: %v", err)
+		}
+		if exitErr.code != exitCodeStale {
+			t.Errorf("unexpected exit code: %d", exitErr.code)
+		}
+		
+		want := "--- " + out + "\n+++ " + out + " (rendered)\n@@ -1,2 +1,3 @@\n # Changes\n * first\n+* second\n"
+		if got != want {
+			t.Errorf("unexpected diff:\n\twant: %q\n\tgot: %q", want, got)
+		}
+		
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if string(b) != "# Changes\n* first\n" {
+			t.Errorf("check should not write the file: %q", string(b))
+		}
+	})
+	
+	t.Run("with missing file should fail", func(t *testing.T) {
+		got, err := check(t, filepath.Join(dir, "missing.md"))
+		var exitErr *exitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("expected exit error, got: %v", err)
+		}
+		if !strings.Contains(got, "+* second\n") {
+			t.Errorf("unexpected diff: %q", got)
+		}
+	})
+	
+	t.Run("with inject should compare the injected file", func(t *testing.T) {
+		out := filepath.Join(dir, "README.md")
+		writeFile(t, out, "intro\n<!-- gitempl:start -->\n# Changes\n* first\n* second\n<!-- gitempl:end -->\n")
+		
+		if _, err := check(t, "--inject", out); err != nil {
+			t.Fatal(err.Error())
+		}
+	})
+	
+	t.Run("without output file should error", func(t *testing.T) {
+		_, err := check(t)
+		if err == nil {
+			t.Fatal("expected error for missing output file")
+		}
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			t.Errorf("unexpected exit error: %v", err)
+		}
+	})
+}
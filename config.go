@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configFiles are the config files discovered in the root of the repo, in
+// order of precedence.
+var configFiles = []string{".gitempl.yaml", ".gitempl.yml", ".gitempl.toml"}
+
+// templateFlags are the flags that provide the template to execute.
+var templateFlags = []string{"template", "template-dir", "builtin"}
+
+// config is the project configuration. Flags provided on the command line
+// take precedence over the values in the config.
+type config struct {
+	// Template is the default template file, relative to the config file.
+	Template string `yaml:"template" toml:"template"`
+	// TemplateDir is the default directory of *.tmpl templates, relative to
+	// the config file.
+	TemplateDir string `yaml:"template_dir" toml:"template_dir"`
+	// Entry is the name of the template to execute.
+	Entry string `yaml:"entry" toml:"entry"`
+	// Builtin is the default builtin template, used when Template is empty.
+	Builtin string `yaml:"builtin" toml:"builtin"`
+	// Output is the default output file, relative to the config file.
+	Output string `yaml:"output" toml:"output"`
+	// Inject replaces only the region between the markers of the output file.
+	Inject      bool   `yaml:"inject" toml:"inject"`
+	InjectStart string `yaml:"inject_start" toml:"inject_start"`
+	InjectEnd   string `yaml:"inject_end" toml:"inject_end"`
+	From        string `yaml:"from" toml:"from"`
+	To          string `yaml:"to" toml:"to"`
+	// Types are the allowed conventional commit types. When empty, all
+	// types are allowed.
+	Types []string `yaml:"types" toml:"types"`
+	// Scopes are the allowed conventional commit scopes. When empty, all
+	// scopes are allowed.
+	Scopes []string `yaml:"scopes" toml:"scopes"`
+	// TypeNames maps conventional commit types to their display names.
+	TypeNames map[string]string `yaml:"type_names" toml:"type_names"`
+	// FirstParent, NoMerges and MergesOnly limit the commits by their merges.
+	FirstParent bool `yaml:"first_parent" toml:"first_parent"`
+	NoMerges    bool `yaml:"no_merges" toml:"no_merges"`
+	MergesOnly  bool `yaml:"merges_only" toml:"merges_only"`
+	// Paths limits the commits to those touching any of the paths, relative
+	// to the root of the repo.
+	Paths []string `yaml:"paths" toml:"paths"`
+	// TagPrefix limits the releases to the tags with the prefix.
+	TagPrefix string `yaml:"tag_prefix" toml:"tag_prefix"`
+	// Forge overrides the forge detected from the origin remote, used to
+	// generate the URLs of commits, files, issues and tags.
+	Forge forgeConfig `yaml:"forge" toml:"forge"`
+	// References are the patterns of the pull request and issues extracted
+	// from the commit messages.
+	References referencesConfig `yaml:"references" toml:"references"`
+	// Keyring is the armored GPG keyring file the commit signatures are
+	// verified against, relative to the config file.
+	Keyring string `yaml:"keyring" toml:"keyring"`
+	// Vars are custom variables available to templates at .Vars.
+	Vars map[string]any `yaml:"vars" toml:"vars"`
+}
+
+func (c *cli) registerConfigFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&c.configFile, "config", "", "config file; defaults to .gitempl.yaml or .gitempl.toml in the root of the repo")
+}
+
+// loadConfig reads the config file and applies its values to the cli for
+// every flag that was not provided on the command line.
+func (c *cli) loadConfig(cmd *cobra.Command, args []string) error {
+	cfg, path, err := readConfig(c.dir, c.configFile)
+	if err != nil {
+		return err
+	}
+	c.cfg = cfg
+	
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(path), p)
+	}
+	
+	flags := cmd.Flags()
+	for _, v := range []struct {
+		flags []string
+		dst   *string
+		val   string
+	}{
+		// a template provided on the command line replaces all templates of the config
+		{flags: templateFlags, dst: &c.tmplDir, val: resolve(cfg.TemplateDir)},
+		{flags: append([]string{"entry"}, templateFlags...), dst: &c.entry, val: cfg.Entry},
+		{flags: templateFlags, dst: &c.builtin, val: cfg.Builtin},
+		{flags: []string{"from"}, dst: &c.from, val: cfg.From},
+		{flags: []string{"to"}, dst: &c.to, val: cfg.To},
+		{flags: []string{"tag-prefix"}, dst: &c.mod.tagPrefix, val: cfg.TagPrefix},
+		{flags: []string{"inject-start"}, dst: &c.injectStart, val: cfg.InjectStart},
+		{flags: []string{"inject-end"}, dst: &c.injectEnd, val: cfg.InjectEnd},
+		{flags: []string{"keyring"}, dst: &c.keyring, val: resolve(cfg.Keyring)},
+	} {
+		changed := slices.ContainsFunc(v.flags, flags.Changed)
+		if v.val != "" && !changed {
+			*v.dst = v.val
+		}
+	}
+	if cfg.Template != "" && !slices.ContainsFunc(templateFlags, flags.Changed) {
+		c.tmpls = []string{resolve(cfg.Template)}
+	}
+	if len(cfg.Paths) > 0 && !flags.Changed("path") {
+		c.mod.paths = cfg.Paths
+	}
+	for _, v := range []struct {
+		flags []string
+		dst   *bool
+		val   bool
+	}{
+		{flags: []string{"inject"}, dst: &c.inject, val: cfg.Inject},
+		{flags: []string{"first-parent"}, dst: &c.merges.firstParent, val: cfg.FirstParent},
+		// the merge flags are mutually exclusive, so either on the command
+		// line replaces both of the config
+		{flags: []string{"no-merges", "merges-only"}, dst: &c.merges.noMerges, val: cfg.NoMerges},
+		{flags: []string{"no-merges", "merges-only"}, dst: &c.merges.mergesOnly, val: cfg.MergesOnly},
+	} {
+		if v.val && !slices.ContainsFunc(v.flags, flags.Changed) {
+			*v.dst = true
+		}
+	}
+	c.cfg.Output = resolve(cfg.Output)
+	
+	return nil
+}
+
+// readConfig reads the config file at the provided path, or the first of the
+// config files found in the dir when path is empty. A missing config file is
+// only an error when the path is provided.
+func readConfig(dir, path string) (config, string, error) {
+	if path != "" {
+		cfg, err := decodeConfig(path)
+		return cfg, path, err
+	}
+	
+	for _, name := range configFiles {
+		path := filepath.Join(dir, name)
+		cfg, err := decodeConfig(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return cfg, path, err
+	}
+	
+	return config{}, "", nil
+}
+
+func decodeConfig(path string) (config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return config{}, err
+	}
+	
+	var cfg config
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &cfg)
+	case ".toml":
+		err = toml.Unmarshal(b, &cfg)
+	default:
+		return config{}, fmt.Errorf("unsupported config file extension %q; must be one of .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return config{}, fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+	
+	return cfg, nil
+}
+
+// typeName returns the display name of the conventional commit type, or the
+// type when there is none configured.
+func typeName(names map[string]string, typ string) string {
+	if name, ok := names[typ]; ok {
+		return name
+	}
+	return typ
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadConfig(t *testing.T) {
+	t.Run("should discover yaml config", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, ".gitempl.yaml"), `
+template: CHANGELOG.tmpl
+from: v1.0.0
+types: [feat, fix]
+type_names:
+  feat: Features
+vars:
+  project: gitempl
+`)
+		
+		cfg, path, err := readConfig(dir, "")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if want := filepath.Join(dir, ".gitempl.yaml"); path != want {
+			t.Errorf("unexpected path:\n\twant: %s\n\tgot: %s", want, path)
+		}
+		if cfg.Template != "CHANGELOG.tmpl" || cfg.From != "v1.0.0" {
+			t.Errorf("unexpected config: %#v", cfg)
+		}
+		mustLen(t, cfg.Types, 2)
+		if got := typeName(cfg.TypeNames, "feat"); got != "Features" {
+			t.Errorf("unexpected type name: %s", got)
+		}
+		if got := typeName(cfg.TypeNames, "fix"); got != "fix" {
+			t.Errorf("unexpected type name: %s", got)
+		}
+		if got := cfg.Vars["project"]; got != "gitempl" {
+			t.Errorf("unexpected var: %v", got)
+		}
+	})
+	
+	t.Run("should discover toml config", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, ".gitempl.toml"), `
+output = "CHANGELOG.md"
+scopes = ["api"]
+
+[type_names]
+fix = "Bug Fixes"
+`)
+		
+		cfg, _, err := readConfig(dir, "")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if cfg.Output != "CHANGELOG.md" {
+			t.Errorf("unexpected output: %s", cfg.Output)
+		}
+		mustLen(t, cfg.Scopes, 1)
+		if got := typeName(cfg.TypeNames, "fix"); got != "Bug Fixes" {
+			t.Errorf("unexpected type name: %s", got)
+		}
+	})
+	
+	t.Run("without config should return zero config", func(t *testing.T) {
+		_, path, err := readConfig(t.TempDir(), "")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if path != "" {
+			t.Errorf("unexpected path: %s", path)
+		}
+	})
+	
+	t.Run("with missing explicit config should error", func(t *testing.T) {
+		_, _, err := readConfig(t.TempDir(), "/does/not/exist.yaml")
+		if err == nil {
+			t.Fatal("expected error for missing config")
+		}
+	})
+}
+
+func TestCmdConfig(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat(api): first", nil)
+	tr.commit("fix(db): second", nil)
+	
+	writeFile(t, filepath.Join(tr.dir, ".gitempl.yaml"), `
+template: docs/changelog.tmpl
+output: docs/CHANGELOG.md
+types: [feat, fix]
+scopes: [api]
+type_names:
+  feat: Features
+vars:
+  title: Changes
+`)
+	writeFile(t, filepath.Join(tr.dir, "docs", "changelog.tmpl"), `# {{ .Vars.title }}
+{{ range .Commits }}* {{ .CC.TypeName }}: {{ .CC.Desc }}
+{{ end }}`)
+	
+	t.Run("should use template and output from config", func(t *testing.T) {
+		cmd := newCmd()
+		cmd.SetArgs([]string{"--dir", tr.dir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		b, err := os.ReadFile(filepath.Join(tr.dir, "docs", "CHANGELOG.md"))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		want := "# Changes\n* Features: first\n* fix: second\n"
+		if got := string(b); got != want {
+			t.Errorf("unexpected output:\n\twant: %q\n\tgot: %q", want, got)
+		}
+	})
+	
+	t.Run("flags should take precedence over config", func(t *testing.T) {
+		dir := t.TempDir()
+		tmpl, out := filepath.Join(dir, "other.tmpl"), filepath.Join(dir, "out.txt")
+		writeFile(t, tmpl, `{{ len .Commits }}`)
+		
+		cmd := newCmd()
+		cmd.SetArgs([]string{"--dir", tr.dir, "--template", tmpl, "--from", "HEAD~1", out})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if got := string(b); got != "1" {
+			t.Errorf("unexpected output: %q", got)
+		}
+	})
+	
+	t.Run("lint should report disallowed scopes", func(t *testing.T) {
+		cmd := newCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"lint", "--dir", tr.dir})
+		
+		err := cmd.Execute()
+		if err == nil {
+			t.Fatal("expected error for disallowed scope")
+		}
+		if want := "1 of 2 commits failed lint"; err.Error() != want {
+			t.Errorf("unexpected error:\n\twant: %s\n\tgot: %s", want, err)
+		}
+		if want := `:1:5: scope "db" is not one of the allowed scopes: api`; !strings.Contains(buf.String(), want) {
+			t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", want, buf.String())
+		}
+	})
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err.Error())
+	}
+}
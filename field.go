@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	commitType       = reflect.TypeOf(commit{})
+	conventionalType = reflect.TypeOf(conventional{})
+)
+
+// fieldPath is the path of a field of a commit, i.e. [CC Desc].
+type fieldPath []string
+
+// newFieldPath resolves the dotted path of an exported field of commit, where
+// any of the names may also be a method without arguments, such as Subject or
+// Author.IsBot. A single name that is not a field of commit is a field of
+// conventional, so that Type is short for CC.Type.
+func newFieldPath(field string) (fieldPath, error) {
+	path := fieldPath(strings.Split(field, "."))
+	if path.resolves(commitType) {
+		return path, nil
+	}
+	if len(path) == 1 && path.resolves(conventionalType) {
+		return fieldPath{"CC", field}, nil
+	}
+	return nil, fmt.Errorf("unknown commit field %q", field)
+}
+
+// resolves reports whether every name of the path is an exported field or
+// method of the type, or of the elements of the pointers and slices along the
+// way.
+func (p fieldPath) resolves(t reflect.Type) bool {
+	_, ok := p.leafType(t)
+	return ok
+}
+
+// leafType returns the type of the last name of the path, without the
+// pointers and slices of its elements.
+func (p fieldPath) leafType(t reflect.Type) (reflect.Type, bool) {
+	elem := func(t reflect.Type) reflect.Type {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		return t
+	}
+	for _, name := range p {
+		t = elem(t)
+		if m, ok := t.MethodByName(name); ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 {
+			t = m.Type.Out(0)
+			continue
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, false
+		}
+		f, ok := t.FieldByName(name)
+		if !ok || !f.IsExported() {
+			return nil, false
+		}
+		t = f.Type
+	}
+	return elem(t), true
+}
+
+// matches reports whether the field of the commit matches the value.
+func (p fieldPath) matches(c commit, value string) bool {
+	return p.matchesValue(reflect.ValueOf(c), func(v reflect.Value) bool {
+		switch x := v.Interface().(type) {
+		case identity:
+			return x.Matches(value)
+		default:
+			return fmt.Sprint(x) == value
+		}
+	})
+}
+
+// matchesValue walks the path from v and reports whether the field matches.
+// A nil pointer never matches, and a slice matches when any of its elements
+// does.
+func (p fieldPath) matchesValue(v reflect.Value, matchFn func(reflect.Value) bool) bool {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return false
+		}
+		return p.matchesValue(v.Elem(), matchFn)
+	case reflect.Slice:
+		for i := range v.Len() {
+			if p.matchesValue(v.Index(i), matchFn) {
+				return true
+			}
+		}
+		return false
+	}
+	
+	if len(p) == 0 {
+		return matchFn(v)
+	}
+	if m := v.MethodByName(p[0]); m.IsValid() {
+		return p[1:].matchesValue(m.Call(nil)[0], matchFn)
+	}
+	return p[1:].matchesValue(v.FieldByName(p[0]), matchFn)
+}
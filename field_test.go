@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCmdKeepByField(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat(api): add thing", nil)
+	tr.commit("fix: other thing", map[string]string{"a.txt": "a"})
+	
+	got, err := renderTemplate(t, tr.dir, `{{ range .Commits.KeepByField "Desc" "add thing" }}{{ .Subject }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := "feat(api): add thing"; got != want {
+		t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", want, got)
+	}
+	
+	// the files are only computed when the template references them
+	got, err = renderTemplate(t, tr.dir, `{{ range .Commits.KeepByField "Files.Path" "a.txt" }}{{ .Subject }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := "fix: other thing"; got != want {
+		t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", want, got)
+	}
+	
+	_, err = renderTemplate(t, tr.dir, `{{ range .Commits.DropByField "CC.Rando" "x" }}{{ end }}`)
+	if err == nil || !strings.Contains(err.Error(), `unknown commit field "CC.Rando"`) {
+		t.Errorf("unexpected error:\n\twant: unknown commit field\n\tgot: %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	
+	"github.com/go-git/go-git/v5"
+)
+
+const (
+	forgeGitHub    = "github"
+	forgeGitLab    = "gitlab"
+	forgeGitea     = "gitea"
+	forgeBitbucket = "bitbucket"
+)
+
+// forgeURLs are the URL patterns of a forge. The patterns are expanded with
+// the {base}, {hash}, {from}, {to}, {ref}, {path}, {id} and {tag}
+// placeholders, where {base} is the URL of the repo.
+type forgeURLs struct {
+	Commit  string `yaml:"commit_url" toml:"commit_url"`
+	Compare string `yaml:"compare_url" toml:"compare_url"`
+	File    string `yaml:"file_url" toml:"file_url"`
+	Issue   string `yaml:"issue_url" toml:"issue_url"`
+	Tag     string `yaml:"tag_url" toml:"tag_url"`
+}
+
+var forgeDefaults = map[string]forgeURLs{
+	forgeGitHub: {
+		Commit:  "{base}/commit/{hash}",
+		Compare: "{base}/compare/{from}...{to}",
+		File:    "{base}/blob/{ref}/{path}",
+		Issue:   "{base}/issues/{id}",
+		Tag:     "{base}/releases/tag/{tag}",
+	},
+	forgeGitLab: {
+		Commit:  "{base}/-/commit/{hash}",
+		Compare: "{base}/-/compare/{from}...{to}",
+		File:    "{base}/-/blob/{ref}/{path}",
+		Issue:   "{base}/-/issues/{id}",
+		Tag:     "{base}/-/tags/{tag}",
+	},
+	forgeGitea: {
+		Commit:  "{base}/commit/{hash}",
+		Compare: "{base}/compare/{from}...{to}",
+		File:    "{base}/src/commit/{ref}/{path}",
+		Issue:   "{base}/issues/{id}",
+		Tag:     "{base}/releases/tag/{tag}",
+	},
+	forgeBitbucket: {
+		Commit:  "{base}/commits/{hash}",
+		Compare: "{base}/branches/compare/{to}%0D{from}",
+		File:    "{base}/src/{ref}/{path}",
+		Issue:   "{base}/issues/{id}",
+		Tag:     "{base}/src/{tag}",
+	},
+}
+
+// forgeConfig overrides the forge detected from the origin remote.
+type forgeConfig struct {
+	// Type is one of github, gitlab, gitea or bitbucket. Defaults to the type
+	// detected from the host of the remote.
+	Type string `yaml:"type" toml:"type"`
+	// URL is the web URL of the repo. Defaults to the URL of the origin
+	// remote.
+	URL string `yaml:"url" toml:"url"`
+	// forgeURLs override the URL patterns of the type.
+	forgeURLs `yaml:",inline"`
+}
+
+// forge generates the web URLs of a repo hosted on a forge. A nil forge
+// generates empty URLs.
+type forge struct {
+	// host is the scheme and host of the forge, i.e. https://github.com.
+	host string
+	// repo is the path of the repo on the host, i.e. org/repo.
+	repo string
+	urls forgeURLs
+	// customIssue is true when the issue URL pattern is configured, which
+	// is required to link issues without a number, such as JIRA-123.
+	customIssue bool
+}
+
+// scpLikeURLRegex matches the scp-like syntax of ssh remotes, such as
+// git@github.com:org/repo.git.
+var scpLikeURLRegex = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// detectForge detects the forge from the URL of the origin remote, with the
+// overrides of the config. A repo without an origin remote, or with a remote
+// on an unknown host, has no forge unless the config provides one.
+func detectForge(r *git.Repository, cfg forgeConfig) (*forge, error) {
+	remoteURL := cfg.URL
+	if remoteURL == "" {
+		remote, err := r.Remote(git.DefaultRemoteName)
+		if errors.Is(err, git.ErrRemoteNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if urls := remote.Config().URLs; len(urls) > 0 {
+			remoteURL = urls[0]
+		}
+	}
+	if remoteURL == "" {
+		return nil, nil
+	}
+	
+	host, repo, err := parseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	
+	typ := cfg.Type
+	if typ == "" {
+		typ = forgeType(host)
+	}
+	urls, ok := forgeDefaults[typ]
+	if !ok && typ != "" {
+		return nil, fmt.Errorf("unknown forge type %q; must be one of github, gitlab, gitea or bitbucket", typ)
+	}
+	
+	for _, v := range []struct {
+		dst *string
+		val string
+	}{
+		{dst: &urls.Commit, val: cfg.Commit},
+		{dst: &urls.Compare, val: cfg.Compare},
+		{dst: &urls.File, val: cfg.File},
+		{dst: &urls.Issue, val: cfg.Issue},
+		{dst: &urls.Tag, val: cfg.Tag},
+	} {
+		if v.val != "" {
+			*v.dst = v.val
+		}
+	}
+	if urls == (forgeURLs{}) {
+		return nil, nil
+	}
+	
+	return &forge{
+		host:        host,
+		repo:        repo,
+		urls:        urls,
+		customIssue: cfg.Issue != "",
+	}, nil
+}
+
+// parseRemoteURL returns the web scheme and host, and the path of the repo
+// of the remote URL. The credentials, the port of ssh URLs and the .git
+// suffix are dropped.
+func parseRemoteURL(remoteURL string) (string, string, error) {
+	if m := scpLikeURLRegex.FindStringSubmatch(remoteURL); m != nil && !strings.Contains(remoteURL, "://") {
+		return "https://" + m[1], strings.TrimSuffix(strings.Trim(m[2], "/"), ".git"), nil
+	}
+	
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse remote url %q: %w", remoteURL, err)
+	}
+	
+	host := u.Host
+	switch u.Scheme {
+	case "http", "https":
+		host = u.Scheme + "://" + host
+	default:
+		host = "https://" + u.Hostname()
+	}
+	return host, strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git"), nil
+}
+
+func forgeType(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "github"):
+		return forgeGitHub
+	case strings.Contains(host, "gitlab"):
+		return forgeGitLab
+	case strings.Contains(host, "gitea"), strings.Contains(host, "codeberg"):
+		return forgeGitea
+	case strings.Contains(host, "bitbucket"):
+		return forgeBitbucket
+	default:
+		return ""
+	}
+}
+
+// funcs returns the template funcs that generate the URLs of the forge.
+func (f *forge) funcs() template.FuncMap {
+	return template.FuncMap{
+		"commitURL":  f.commitURL,
+		"compareURL": f.compareURL,
+		"fileURL":    f.fileURL,
+		"issueURL":   f.issueURL,
+		"tagURL":     f.tagURL,
+	}
+}
+
+func (f *forge) commitURL(hash string) string {
+	if f == nil {
+		return ""
+	}
+	return f.expand(f.urls.Commit, "", "{hash}", hash)
+}
+
+func (f *forge) compareURL(from, to string) string {
+	if f == nil {
+		return ""
+	}
+	return f.expand(f.urls.Compare, "", "{from}", from, "{to}", to)
+}
+
+// fileURL returns the URL of the file at the ref, which is a commit hash,
+// branch or tag.
+func (f *forge) fileURL(ref, path string) string {
+	if f == nil {
+		return ""
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return f.expand(f.urls.File, "", "{ref}", ref, "{path}", strings.Join(segments, "/"))
+}
+
+// issueURL returns the URL of the issue, which is an issue reference of a
+// commit, or a number or reference such as 12, #12 or org/repo#12. Issues
+// without a number, such as JIRA-123, only have a URL with a configured
+// issue_url pattern.
+func (f *forge) issueURL(issue any) (string, error) {
+	var ref issueRef
+	switch v := issue.(type) {
+	case issueRef:
+		ref = v
+	case *issueRef:
+		if v == nil {
+			return "", nil
+		}
+		ref = *v
+	case int:
+		ref.ID = strconv.Itoa(v)
+	case string:
+		repo, id, ok := strings.Cut(v, "#")
+		if !ok {
+			repo, id = "", v
+		}
+		ref = issueRef{Repo: repo, ID: id}
+	default:
+		return "", fmt.Errorf("issueURL: unsupported issue of type %T", issue)
+	}
+	
+	if f == nil || (!isNumber(ref.ID) && !f.customIssue) {
+		return "", nil
+	}
+	return f.expand(f.urls.Issue, ref.Repo, "{id}", url.PathEscape(ref.ID)), nil
+}
+
+func (f *forge) tagURL(tag string) string {
+	if f == nil {
+		return ""
+	}
+	return f.expand(f.urls.Tag, "", "{tag}", url.PathEscape(tag))
+}
+
+// expand replaces the placeholders of the pattern. The {base} is the URL of
+// the repo, or of the other repo on the same host when provided.
+func (f *forge) expand(pattern, repo string, oldnew ...string) string {
+	if pattern == "" {
+		return ""
+	}
+	if repo == "" {
+		repo = f.repo
+	}
+	oldnew = append(oldnew, "{base}", f.host+"/"+repo)
+	return strings.NewReplacer(oldnew...).Replace(pattern)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultInjectStart = "<!-- gitempl:start -->"
+	defaultInjectEnd   = "<!-- gitempl:end -->"
+)
+
+func (c *cli) registerInjectFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&c.inject, "inject", false, "replace only the region between the start and end markers of the existing output file")
+	cmd.Flags().StringVar(&c.injectStart, "inject-start", defaultInjectStart, "marker at the start of the region replaced by --inject")
+	cmd.Flags().StringVar(&c.injectEnd, "inject-end", defaultInjectEnd, "marker at the end of the region replaced by --inject")
+}
+
+// injectFile reads the file and replaces the region between the start and end
+// markers with the rendered output. The markers are kept, and must each be
+// found exactly once in the file.
+func injectFile(file, rendered, start, end string) (string, error) {
+	if file == "" {
+		return "", errors.New("an output file is required with --inject")
+	}
+	
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file to inject into: %w", err)
+	}
+	
+	out, err := inject(string(b), rendered, start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to inject into %s: %w", file, err)
+	}
+	return out, nil
+}
+
+func inject(content, rendered, start, end string) (string, error) {
+	if start == "" || end == "" {
+		return "", errors.New("start and end markers must not be empty")
+	}
+	
+	for _, marker := range []string{start, end} {
+		switch n := strings.Count(content, marker); n {
+		case 0:
+			return "", fmt.Errorf("marker %q not found", marker)
+		case 1:
+		default:
+			return "", fmt.Errorf("marker %q found %d times; must be found exactly once", marker, n)
+		}
+	}
+	
+	startIdx := strings.Index(content, start) + len(start)
+	endIdx := strings.Index(content, end)
+	if endIdx < startIdx {
+		return "", fmt.Errorf("marker %q must be after marker %q", end, start)
+	}
+	
+	if !strings.HasSuffix(rendered, "\n") {
+		rendered += "\n"
+	}
+	return content[:startIdx] + "\n" + rendered + content[endIdx:], nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInject(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		rendered string
+		want     string
+	}{
+		{
+			name:     "should replace region between markers",
+			content:  "# Title\n<!-- gitempl:start -->\nold\n<!-- gitempl:end -->\nfooter\n",
+			rendered: "new\n",
+			want:     "# Title\n<!-- gitempl:start -->\nnew\n<!-- gitempl:end -->\nfooter\n",
+		},
+		{
+			name:     "with empty region should insert",
+			content:  "<!-- gitempl:start --><!-- gitempl:end -->",
+			rendered: "new",
+			want:     "<!-- gitempl:start -->\nnew\n<!-- gitempl:end -->",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inject(tt.content, tt.rendered, defaultInjectStart, defaultInjectEnd)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got != tt.want {
+				t.Errorf("unexpected output:\n\twant: %q\n\tgot: %q", tt.want, got)
+			}
+		})
+	}
+	
+	errTests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "with missing start marker should error",
+			content: "old\n<!-- gitempl:end -->",
+			wantErr: `marker "<!-- gitempl:start -->" not found`,
+		},
+		{
+			name:    "with missing end marker should error",
+			content: "<!-- gitempl:start -->\nold",
+			wantErr: `marker "<!-- gitempl:end -->" not found`,
+		},
+		{
+			name:    "with duplicated marker should error",
+			content: "<!-- gitempl:start --><!-- gitempl:end --><!-- gitempl:start -->",
+			wantErr: `marker "<!-- gitempl:start -->" found 2 times; must be found exactly once`,
+		},
+		{
+			name:    "with end before start should error",
+			content: "<!-- gitempl:end --><!-- gitempl:start -->",
+			wantErr: `marker "<!-- gitempl:end -->" must be after marker "<!-- gitempl:start -->"`,
+		},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inject(tt.content, "new", defaultInjectStart, defaultInjectEnd)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("unexpected error:\n\twant: %s\n\tgot: %s", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCmdInject(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", nil)
+	tr.commit("fix: second", nil)
+	
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "changelog.tmpl")
+	writeFile(t, tmpl, `{{ range .Commits }}* {{ .CC.Desc }}
+{{ end }}`)
+	
+	t.Run("should replace only the region between markers", func(t *testing.T) {
+		out := filepath.Join(dir, "README.md")
+		writeFile(t, out, "# Hand written\n\n[//]: # (start)\nstale\n[//]: # (end)\n\nMore hand written.\n")
+		if err := os.Chmod(out, 0o644); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		cmd := newCmd()
+		cmd.SetArgs([]string{
+			"--dir", tr.dir, "-t", tmpl, "--inject",
+			"--inject-start", "[//]: # (start)", "--inject-end", "[//]: # (end)",
+			out,
+		})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		want := "# Hand written\n\n[//]: # (start)\n* first\n* second\n[//]: # (end)\n\nMore hand written.\n"
+		if got := string(b); got != want {
+			t.Errorf("unexpected output:\n\twant: %q\n\tgot: %q", want, got)
+		}
+		
+		fi, err := os.Stat(out)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if got := fi.Mode().Perm(); got != 0o644 {
+			t.Errorf("unexpected file mode:\n\twant: %s\n\tgot: %s", os.FileMode(0o644), got)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".tmp") {
+				t.Errorf("unexpected temp file left behind: %s", e.Name())
+			}
+		}
+	})
+	
+	t.Run("with missing markers should error and leave file untouched", func(t *testing.T) {
+		out := filepath.Join(dir, "CHANGELOG.md")
+		writeFile(t, out, "# Hand written\n")
+		
+		cmd := newCmd()
+		cmd.SetArgs([]string{"--dir", tr.dir, "-t", tmpl, "--inject", out})
+		err := cmd.Execute()
+		if err == nil {
+			t.Fatal("expected error for missing markers")
+		}
+		if want := "not found"; !strings.Contains(err.Error(), want) {
+			t.Errorf("unexpected error: %s", err)
+		}
+		
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if got := string(b); got != "# Hand written\n" {
+			t.Errorf("unexpected file content: %q", got)
+		}
+	})
+	
+	t.Run("without output file should error", func(t *testing.T) {
+		cmd := newCmd()
+		cmd.SetArgs([]string{"--dir", tr.dir, "-t", tmpl, "--inject"})
+		if err := cmd.Execute(); err == nil {
+			t.Fatal("expected error for missing output file")
+		}
+	})
+}
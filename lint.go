@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	
+	"github.com/conventionalcommit/parser"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+type lintIssue struct {
+	Hash    string
+	Subject string
+	Err     error
+	Line    int
+	Column  int
+}
+
+func (l lintIssue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s\n\t%s", l.Hash[:min(7, len(l.Hash))], l.Line, l.Column, l.Err, l.Subject)
+}
+
+func (c *cli) newLintCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:          "lint",
+		Short:        "report commits that are not conventional commits or not allowed by the config",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := git.PlainOpen(c.dir)
+			if err != nil {
+				return err
+			}
+			
+			allowed := lintRules{types: c.cfg.Types, scopes: c.cfg.Scopes}
+			issues, total, err := lintCommits(r, c.from, c.to, c.merges, allowed)
+			if err != nil {
+				return err
+			}
+			
+			return writeLintIssues(cmd.OutOrStdout(), issues, total)
+		},
+		Example: `  # lint the commits of a feature branch
+> gitempl lint --from origin/main
+
+# lint the commits of a branch with merge commits
+> gitempl lint --from origin/main --no-merges`,
+	}
+	
+	cmd.Flags().StringVar(&c.from, "from", "", "revision to start from (exclusive), commits reachable from it are omitted; same as A in git log A..B")
+	cmd.Flags().StringVar(&c.to, "to", "HEAD", "revision to end at (inclusive); same as B in git log A..B")
+	c.registerMergeFlags(&cmd)
+	
+	return &cmd
+}
+
+// lintRules restricts the conventional commits that are allowed.
+type lintRules struct {
+	// types are the allowed types. When empty, all types are allowed.
+	types []string
+	// scopes are the allowed scopes. When empty, all scopes are allowed.
+	scopes []string
+}
+
+// check returns the column of the header where the disallowed type or scope
+// starts, and an error describing it.
+func (l lintRules) check(cc conventional) (int, error) {
+	if len(l.types) > 0 && !slices.Contains(l.types, cc.Type) {
+		return 1, fmt.Errorf("type %q is not one of the allowed types: %s", cc.Type, strings.Join(l.types, ", "))
+	}
+	if cc.Scope != "" && len(l.scopes) > 0 && !slices.Contains(l.scopes, cc.Scope) {
+		return len([]rune(cc.Type)) + 2, fmt.Errorf("scope %q is not one of the allowed scopes: %s", cc.Scope, strings.Join(l.scopes, ", "))
+	}
+	return 0, nil
+}
+
+// lintCommits parses the commits in the from..to range with the same parser
+// used for rendering templates and returns an issue for each commit that is
+// not a conventional commit, or uses a type or scope that is not allowed,
+// oldest first, along with the number of commits linted. The commits are
+// limited by their merges the same as when rendering.
+func lintCommits(r *git.Repository, from, to string, merges mergeOpts, allowed lintRules) ([]lintIssue, int, error) {
+	iter, err := logRange(r, from, to, merges.firstParent)
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	var (
+		p      = parser.New()
+		issues []lintIssue
+		total  int
+	)
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !merges.include(c) {
+			return nil
+		}
+		total++
+		
+		issue := lintIssue{Hash: c.Hash.String(), Line: 1}
+		issue.Subject, _, _ = strings.Cut(strings.TrimSpace(c.Message), "\n")
+		
+		cc, err := parseConventional(p, c.Message)
+		if err != nil {
+			issue.Err = err
+			issue.Line, issue.Column = parseErrorPosition(c.Message)
+		} else {
+			issue.Column, issue.Err = allowed.check(cc)
+		}
+		
+		if issue.Err != nil {
+			issues = append(issues, issue)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	slices.Reverse(issues)
+	
+	return issues, total, nil
+}
+
+func writeLintIssues(w io.Writer, issues []lintIssue, total int) error {
+	for _, issue := range issues {
+		if _, err := fmt.Fprintln(w, issue); err != nil {
+			return err
+		}
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d of %d commits failed lint", len(issues), total)
+	}
+	return nil
+}
+
+var footerLineRegex = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[\p{L}\p{N}-]+)(: | #)`)
+
+// parseErrorPosition returns the 1-based line and column where the message
+// stops following the conventional commit grammar. The parser does not
+// report positions, so this follows the same grammar the parser's lexer does.
+func parseErrorPosition(msg string) (int, int) {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	header := []rune(lines[0])
+	
+	i := 0
+	for ; i < len(header); i++ {
+		r := header[i]
+		if r == ':' || r == '!' || r == '(' {
+			break
+		}
+		if !isValidTypeChar(r) {
+			return 1, i + 1
+		}
+	}
+	if i == len(header) {
+		return 1, i + 1
+	}
+	
+	if header[i] == '(' {
+		start := i
+		for i++; i < len(header) && header[i] != ')'; i++ {
+			if header[i] == '(' {
+				return 1, i + 1
+			}
+		}
+		if i == len(header) || i == start+1 {
+			return 1, i + 1
+		}
+		i++
+	}
+	
+	if i < len(header) && header[i] == '!' {
+		i++
+	}
+	if !strings.HasPrefix(string(header[i:]), ": ") {
+		return 1, i + 1
+	}
+	
+	if len(lines) == 1 {
+		return 1, 1
+	}
+	if lines[1] != "" {
+		return 2, 1
+	}
+	
+	for n := 2; n < len(lines); n++ {
+		if footerLineRegex.MatchString(lines[n]) && lines[n-1] != "" {
+			return n + 1, 1
+		}
+	}
+	
+	return 1, 1
+}
+
+// isValidTypeChar matches the parser's type grammar:
+// <any UTF8-octets except newline or parens or ":" or "!:" or whitespace>+
+func isValidTypeChar(r rune) bool {
+	switch r {
+	case '(', ')', ':':
+		return false
+	default:
+		return !unicode.IsSpace(r)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	
+	"github.com/conventionalcommit/parser"
+)
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		line int
+		col  int
+	}{
+		{
+			name: "missing type delimiter",
+			msg:  "not conventional",
+			line: 1,
+			col:  4,
+		},
+		{
+			name: "missing description",
+			msg:  "feat",
+			line: 1,
+			col:  5,
+		},
+		{
+			name: "empty scope",
+			msg:  "feat(): add thing",
+			line: 1,
+			col:  6,
+		},
+		{
+			name: "unclosed scope",
+			msg:  "feat(api: add thing",
+			line: 1,
+			col:  20,
+		},
+		{
+			name: "missing space after delimiter",
+			msg:  "feat(api)!:add thing",
+			line: 1,
+			col:  11,
+		},
+		{
+			name: "missing empty line after header",
+			msg:  "feat: add thing\nbody",
+			line: 2,
+			col:  1,
+		},
+		{
+			name: "missing empty line after body",
+			msg:  "feat: add thing\n\nbody\nRefs: #12",
+			line: 4,
+			col:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parser.New().Parse(tt.msg); err == nil {
+				t.Fatalf("expected parser to fail for message: %q", tt.msg)
+			}
+			
+			line, col := parseErrorPosition(tt.msg)
+			if line != tt.line || col != tt.col {
+				t.Errorf("parseErrorPosition(%q) = %d:%d, want %d:%d", tt.msg, line, col, tt.line, tt.col)
+			}
+		})
+	}
+}
+
+func TestLintCmd(t *testing.T) {
+	tr := newTestRepo(t)
+	first := tr.commit("feat: first", nil)
+	tr.commit("WIP", nil)
+	tr.commit("fix(): second", nil)
+	tr.commit("fix: third", nil)
+	
+	t.Run("with non conventional commits should report and fail", func(t *testing.T) {
+		cmd := newCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"lint", "--dir", tr.dir})
+		
+		err := cmd.Execute()
+		if err == nil {
+			t.Fatal("expected error for non conventional commits")
+		}
+		if want := "2 of 4 commits failed lint"; err.Error() != want {
+			t.Errorf("unexpected error:\n\twant: %s\n\tgot: %s", want, err)
+		}
+		
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		mustLen(t, lines, 4)
+		if !strings.HasSuffix(lines[0], ":1:4: header: missing scope or description") {
+			t.Errorf("unexpected first issue: %s", lines[0])
+		}
+		if want := "\tWIP"; lines[1] != want {
+			t.Errorf("unexpected subject:\n\twant: %q\n\tgot: %q", want, lines[1])
+		}
+		if !strings.HasSuffix(lines[2], ":1:5: scope is empty") {
+			t.Errorf("unexpected second issue: %s", lines[2])
+		}
+	})
+	
+	t.Run("with conventional range should pass", func(t *testing.T) {
+		issues, total, err := lintCommits(tr.repo, "HEAD~1", "HEAD", mergeOpts{}, lintRules{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		mustLen(t, issues, 0)
+		if total != 1 {
+			t.Errorf("unexpected total: %d", total)
+		}
+	})
+	
+	t.Run("with only first commit should pass", func(t *testing.T) {
+		cmd := newCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"lint", "--dir", tr.dir, "--to", first.String()})
+		
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+	})
+}
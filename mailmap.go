@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// mailmapFile is the mailmap of the repo, relative to the root of the
+// worktree, or of the tree of HEAD for a bare repo.
+const mailmapFile = ".mailmap"
+
+// mailmap maps the names and emails of commits to the canonical identities of
+// the people, as documented by gitmailmap(5).
+type mailmap struct {
+	entries []mailmapEntry
+}
+
+type mailmapEntry struct {
+	// name and email are the canonical identity, either may be empty to
+	// keep the name or email of the commit.
+	name  string
+	email string
+	// commitName and commitEmail are matched against the commit, ignoring
+	// case. An empty commitName matches any name.
+	commitName  string
+	commitEmail string
+}
+
+// readMailmap reads the .mailmap of the repo followed by the file of the
+// mailmap.file git config, whose entries take precedence. A repo without
+// either has a nil mailmap.
+func readMailmap(r *git.Repository) (*mailmap, error) {
+	var m mailmap
+	
+	root := ""
+	if wt, err := r.Worktree(); err == nil {
+		root = wt.Filesystem.Root()
+		if err := m.readFile(filepath.Join(root, mailmapFile)); err != nil {
+			return nil, err
+		}
+	} else if errors.Is(err, git.ErrIsBareRepository) {
+		if err := m.readHEAD(r); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+	
+	cfg, err := r.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return nil, err
+	}
+	if file := cfg.Raw.Section("mailmap").Option("file"); file != "" {
+		if rest, ok := strings.CutPrefix(file, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			file = filepath.Join(home, rest)
+		} else if !filepath.IsAbs(file) {
+			file = filepath.Join(root, file)
+		}
+		if err := m.readFile(file); err != nil {
+			return nil, err
+		}
+	}
+	
+	if len(m.entries) == 0 {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+// readFile reads the entries of the file, which is skipped when missing.
+func (m *mailmap) readFile(file string) error {
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	
+	return m.read(f)
+}
+
+// readHEAD reads the entries of the .mailmap in the tree of HEAD, which is
+// skipped when missing.
+func (m *mailmap) readHEAD(r *git.Repository) error {
+	head, err := r.Head()
+	if err != nil {
+		// an empty repo has no commits to map
+		return nil
+	}
+	c, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	f, err := c.File(mailmapFile)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	
+	rc, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	
+	return m.read(rc)
+}
+
+func (m *mailmap) read(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		e, err := parseMailmapLine(line)
+		if err != nil {
+			return fmt.Errorf("invalid mailmap line %d %q: %w", n, sc.Text(), err)
+		}
+		m.entries = append(m.entries, e)
+	}
+	return sc.Err()
+}
+
+// parseMailmapLine parses one of the forms of a mailmap line:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func parseMailmapLine(line string) (mailmapEntry, error) {
+	var (
+		names  []string
+		emails []string
+	)
+	for {
+		name, rest, ok := strings.Cut(line, "<")
+		if !ok {
+			if strings.TrimSpace(line) != "" {
+				return mailmapEntry{}, errors.New("unexpected text after the last email")
+			}
+			break
+		}
+		email, rest, ok := strings.Cut(rest, ">")
+		if !ok {
+			return mailmapEntry{}, errors.New("missing closing >")
+		}
+		names = append(names, strings.TrimSpace(name))
+		emails = append(emails, strings.TrimSpace(email))
+		line = rest
+	}
+	
+	switch len(emails) {
+	case 1:
+		return mailmapEntry{name: names[0], commitEmail: emails[0]}, nil
+	case 2:
+		return mailmapEntry{
+			name:        names[0],
+			email:       emails[0],
+			commitName:  names[1],
+			commitEmail: emails[1],
+		}, nil
+	default:
+		return mailmapEntry{}, fmt.Errorf("expected 1 or 2 emails, got %d", len(emails))
+	}
+}
+
+// apply returns the canonical identity of the commit identity. An entry
+// matching both the name and email wins over one matching only the email,
+// and later entries win over earlier ones.
+func (m *mailmap) apply(id identity) identity {
+	if m == nil {
+		return id
+	}
+	
+	var match *mailmapEntry
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		e := &m.entries[i]
+		if !strings.EqualFold(e.commitEmail, id.Email) {
+			continue
+		}
+		if e.commitName != "" {
+			if strings.EqualFold(e.commitName, id.Name) {
+				match = e
+				break
+			}
+			continue
+		}
+		if match == nil {
+			match = e
+		}
+	}
+	if match == nil {
+		return id
+	}
+	
+	if match.name != "" {
+		id.Name = match.name
+	}
+	if match.email != "" {
+		id.Email = match.email
+	}
+	return id
+}
+
+// applyMailmap replaces the identities of the commit with their canonical
+// identities.
+func (c *commit) applyMailmap(m *mailmap) {
+	if m == nil {
+		return
+	}
+	c.Author = m.apply(c.Author)
+	c.Committer = m.apply(c.Committer)
+	
+	// the co-authors are shared with the cache, which keeps the identities
+	// of the commit
+	coAuthors := make([]identity, 0, len(c.CoAuthors))
+	for _, id := range c.CoAuthors {
+		coAuthors = append(coAuthors, m.apply(id))
+	}
+	c.CoAuthors = coAuthors
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMailmap_apply(t *testing.T) {
+	var m mailmap
+	err := m.read(strings.NewReader(`# comment
+Jane Doe <jane@example.com>
+<jane@example.com> <jane@old.example.com>
+Jane Doe <jane@example.com> Jane <JANE@laptop.local> # trailing comment
+John Smith <john@example.com> <john@example.com>
+Johnny <johnny@example.com> Johnny <john@example.com>
+`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	
+	tests := []struct {
+		name string
+		id   identity
+		want identity
+	}{
+		{
+			name: "name by email",
+			id:   identity{Name: "jdoe", Email: "jane@example.com"},
+			want: identity{Name: "Jane Doe", Email: "jane@example.com"},
+		},
+		{
+			name: "email by email",
+			id:   identity{Name: "Jane", Email: "jane@old.example.com"},
+			want: identity{Name: "Jane", Email: "jane@example.com"},
+		},
+		{
+			name: "name and email by name and email ignoring case",
+			id:   identity{Name: "jane", Email: "jane@laptop.local"},
+			want: identity{Name: "Jane Doe", Email: "jane@example.com"},
+		},
+		{
+			name: "name and email entry wins over email entry",
+			id:   identity{Name: "Johnny", Email: "john@example.com"},
+			want: identity{Name: "Johnny", Email: "johnny@example.com"},
+		},
+		{
+			name: "email entry for other names",
+			id:   identity{Name: "J. Smith", Email: "john@example.com"},
+			want: identity{Name: "John Smith", Email: "john@example.com"},
+		},
+		{
+			name: "unmapped",
+			id:   identity{Name: "Other", Email: "other@example.com"},
+			want: identity{Name: "Other", Email: "other@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.apply(tt.id); got != tt.want {
+				t.Errorf("identities do not match:\n\twant: %s\n\tgot: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseMailmapLine_invalid(t *testing.T) {
+	for _, line := range []string{
+		"Jane Doe",
+		"Jane Doe <jane@example.com",
+		"<a@example.com> <b@example.com> <c@example.com>",
+		"<a@example.com> trailing",
+	} {
+		if _, err := parseMailmapLine(line); err == nil {
+			t.Errorf("expected error for line %q", line)
+		}
+	}
+}
+
+func TestCmdMailmap(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first\n\nCo-authored-by: jsmith <john@old.example.com>", nil)
+	
+	render := func() string {
+		t.Helper()
+		
+		got, err := renderTemplate(t, tr.dir, `{{ range .Commits.KeepByField "Author" "Jane D." }}{{ .Author }}|{{ .Committer }}{{ end }}
+{{ range .Contributors }}{{ . }}
+{{ end }}`)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return got
+	}
+	
+	if got, want := render(), "\nJane Doe <jane@example.com>\njsmith <john@old.example.com>\n"; got != want {
+		t.Errorf("unexpected output without mailmap:\n\twant: %s\n\tgot: %s", want, got)
+	}
+	
+	writeFile(t, filepath.Join(tr.dir, ".mailmap"), "Jane D. <jane@example.com>\n")
+	mailmapFile := filepath.Join(t.TempDir(), "mailmap")
+	writeFile(t, mailmapFile, "John Smith <john@example.com> <john@old.example.com>\n")
+	cfg, err := tr.repo.Config()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cfg.Raw.Section("mailmap").SetOption("file", mailmapFile)
+	if err := tr.repo.SetConfig(cfg); err != nil {
+		t.Fatal(err.Error())
+	}
+	
+	// the commit is cached by the first render, the mailmap must still apply
+	want := "Jane D. <jane@example.com>|Jane D. <jane@example.com>\nJane D. <jane@example.com>\nJohn Smith <john@example.com>\n"
+	if got := render(); got != want {
+		t.Errorf("unexpected output with mailmap:\n\twant: %s\n\tgot: %s", want, got)
+	}
+}
@@ -1,23 +1,34 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"slices"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
 	
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/conventionalcommit/parser"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/spf13/cobra"
 )
 
 func main() {
 	if err := newCmd().Execute(); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -28,17 +39,37 @@ func newCmd() *cobra.Command {
 }
 
 type cli struct {
-	dir  string
-	tmpl string
+	dir     string
+	tmpls   []string
+	tmplDir string
+	entry   string
+	builtin string
+	from    string
+	to      string
+	bump    bumpRules
+	mod     module
+	jobs    int
+	noCache bool
+	merges  mergeOpts
+	keyring string
+	
+	inject      bool
+	injectStart string
+	injectEnd   string
+	check       bool
+	
+	configFile string
+	cfg        config
 }
 
 func (c *cli) newCmd() *cobra.Command {
 	cmd := cobra.Command{
-		Use:          "gitempl [--dir|-d] $OPTIONAL_FILENAME",
-		Short:        "a simple doc generator that is conventional commit aware",
-		RunE:         c.runE,
-		Args:         cobra.MaximumNArgs(1),
-		SilenceUsage: true,
+		Use:               "gitempl [--dir|-d] $OPTIONAL_FILENAME",
+		Short:             "a simple doc generator that is conventional commit aware",
+		RunE:              c.runE,
+		PersistentPreRunE: c.loadConfig,
+		Args:              cobra.MaximumNArgs(1),
+		SilenceUsage:      true,
 		Example: `  # execute from root of git repo with inline template writes to stdout
 > gitempl <<EOF
 {{ range .Commits }}
@@ -54,11 +85,57 @@ EOF
 # execute with a git repo in arbitrary directory (not in PWD) with template file
 # writes to stdout
 > gitempl -d $PATH_TO_GIT_REPO -t $FILE_TEMPLATE
+
+# execute with only the commits between two revisions, same as git log v1.0.0..v1.1.0
+> gitempl --from v1.0.0 --to v1.1.0 -t $FILE_TEMPLATE
+
+# execute with only the merge commits of the main branch
+> gitempl --first-parent --merges-only -t $FILE_TEMPLATE
+
+# execute with a template that includes partials from a directory of templates
+> gitempl --template-dir $DIR_TEMPLATES --entry changelog.tmpl
+
+# execute with a builtin template, see gitempl templates list
+> gitempl --builtin keepachangelog CHANGELOG.md
+
+# execute for a module of a monorepo, with only the commits touching it and its prefixed tags
+> gitempl --path pkg/foo --tag-prefix pkg/foo/ -t $FILE_TEMPLATE pkg/foo/CHANGELOG.md
+
+# update only the region between the <!-- gitempl:start --> and <!-- gitempl:end --> markers
+> gitempl --inject -t $FILE_TEMPLATE README.md
+
+# fail with exit code 3 and print a diff when the output file is not up to date
+> gitempl --check -t $FILE_TEMPLATE $FILE
+
+# execute with the template, output file and revision range of a config file
+> gitempl --config $FILE_CONFIG
 `,
 	}
 	
-	cmd.Flags().StringVarP(&c.dir, "dir", "d", ".", "directory of git repo")
-	cmd.Flags().StringVarP(&c.tmpl, "template", "t", "", "template to execute; defaults to stdin")
+	cmd.PersistentFlags().StringVarP(&c.dir, "dir", "d", ".", "directory of git repo")
+	cmd.Flags().StringArrayVarP(&c.tmpls, "template", "t", nil, "template to execute; defaults to stdin. May be repeated to parse multiple files, the first is executed unless --entry is provided")
+	cmd.Flags().StringVar(&c.tmplDir, "template-dir", "", "directory of *.tmpl templates to parse; requires --entry unless --template is provided")
+	cmd.Flags().StringVar(&c.entry, "entry", "", "name of the template to execute, either a file name or the name of a defined template")
+	cmd.Flags().StringVar(&c.builtin, "builtin", "", "builtin template to execute, one of keepachangelog, github-release, plain or json; see gitempl templates list")
+	cmd.MarkFlagsMutuallyExclusive("template", "builtin")
+	cmd.MarkFlagsMutuallyExclusive("template-dir", "builtin")
+	cmd.Flags().StringVar(&c.from, "from", "", "revision to start from (exclusive), commits reachable from it are omitted; same as A in git log A..B")
+	cmd.Flags().StringVar(&c.to, "to", "HEAD", "revision to end at (inclusive); same as B in git log A..B")
+	cmd.Flags().IntVarP(&c.jobs, "jobs", "j", runtime.NumCPU(), "number of commits diffed concurrently to compute the file stats")
+	cmd.Flags().BoolVar(&c.noCache, "no-cache", false, "parse every commit instead of reading and writing the cache in .git/gitempl-cache")
+	cmd.Flags().StringVar(&c.keyring, "keyring", "", "armored GPG keyring file to verify the commit signatures against")
+	c.registerBumpFlags(&cmd)
+	c.registerConfigFlags(&cmd)
+	c.registerModuleFlags(&cmd)
+	c.registerMergeFlags(&cmd)
+	c.registerInjectFlags(&cmd)
+	cmd.Flags().BoolVar(&c.check, "check", false, "compare the rendered output with the output file instead of writing it, printing a diff and exiting with code 3 when they differ")
+	
+	cmd.AddCommand(
+		c.newLintCmd(),
+		newTemplatesCmd(),
+		c.newVersionCmd(),
+	)
 	
 	return &cmd
 }
@@ -69,53 +146,176 @@ func (c *cli) runE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	
-	commits, err := parseGitTemplVars(r)
+	fg, err := detectForge(r, c.cfg.Forge)
 	if err != nil {
 		return err
 	}
 	
-	t, err := c.template(cmd.InOrStdin())
+	stats := make(statsIndex)
+	t, err := c.template(cmd.InOrStdin(), fg, stats)
 	if err != nil {
 		return err
 	}
 	
-	var file string
+	refs, err := newRefParser(c.cfg.References)
+	if err != nil {
+		return err
+	}
+	
+	mm, err := readMailmap(r)
+	if err != nil {
+		return err
+	}
+	
+	keyring, err := readKeyring(c.keyring)
+	if err != nil {
+		return err
+	}
+	
+	var cache *commitCache
+	if !c.noCache {
+		cache = openCommitCache(r)
+	}
+	
+	commits, err := parseGitTemplVars(r, parseOpts{
+		from:      c.from,
+		to:        c.to,
+		typeNames: c.cfg.TypeNames,
+		paths:     c.mod.paths,
+		stats:     templateNeedsStats(t),
+		jobs:      c.jobs,
+		cache:     cache,
+		merges:    c.merges,
+		refs:      refs,
+		mailmap:   mm,
+		keyring:   keyring,
+	})
+	if err != nil {
+		return err
+	}
+	stats.add(commits)
+	
+	releases, unreleased, err := parseReleases(r, commits, c.mod.tagPrefix)
+	if err != nil {
+		return err
+	}
+	
+	var next versionBump
+	if templateReferences(t, "NextVersion", "json") {
+		next, err = nextVersion(r, c.to, c.mod, c.bump, cache, c.jobs)
+		if err != nil {
+			return err
+		}
+	}
+	if err := cache.save(); err != nil {
+		// the cache is an optimisation, a read-only repo must still render
+		fmt.Fprintf(cmd.ErrOrStderr(), "failed to write commit cache: %s\n", err)
+	}
+	
+	file := c.cfg.Output
 	if len(args) > 0 {
 		file = args[0]
 	}
 	
+	in := input{
+		Commits:      commits,
+		Contributors: commitSlc(commits).Contributors(),
+		NextVersion:  next,
+		Releases:     releases,
+		Unreleased:   unreleased,
+		Vars:         c.cfg.Vars,
+	}
+	
+	if c.inject || c.check {
+		var buf strings.Builder
+		if err := t.Execute(&buf, in); err != nil {
+			return err
+		}
+		
+		rendered := buf.String()
+		if c.inject {
+			rendered, err = injectFile(file, rendered, c.injectStart, c.injectEnd)
+			if err != nil {
+				return err
+			}
+		}
+		if c.check {
+			return checkFile(cmd.OutOrStdout(), file, rendered)
+		}
+		
+		w, closeFn, err := c.output(file, cmd.OutOrStdout())
+		if err != nil {
+			return err
+		}
+		defer closeFn() // in case of early exit
+		
+		if _, err := io.WriteString(w, rendered); err != nil {
+			return err
+		}
+		return closeFn()
+	}
+	
 	w, closeFn, err := c.output(file, cmd.OutOrStdout())
 	if err != nil {
 		return err
 	}
 	defer closeFn() // in case of early exit
 	
-	err = t.Execute(w, input{Commits: commits})
-	if err != nil {
+	if err := t.Execute(w, in); err != nil {
 		return err
 	}
 	
 	return closeFn()
 }
 
-func (c *cli) template(stdin io.Reader) (*template.Template, error) {
-	var (
-		b   []byte
-		err error
-	)
-	if c.tmpl != "" {
-		b, err = os.ReadFile(c.tmpl)
-	} else {
-		b, err = io.ReadAll(stdin)
+func (c *cli) template(stdin io.Reader, fg *forge, stats statsIndex) (*template.Template, error) {
+	t := template.New("template").Funcs(funcMap).Funcs(fg.funcs()).Funcs(statsFuncs(fg, stats))
+	
+	switch {
+	case c.builtin != "":
+		tmpl, err := builtinTemplate(c.builtin)
+		if err != nil {
+			return nil, err
+		}
+		return t.Parse(tmpl)
+	case len(c.tmpls) == 0 && c.tmplDir == "":
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		return t.Parse(string(b))
 	}
+	
+	files := slices.Clone(c.tmpls)
+	if c.tmplDir != "" {
+		matches, err := filepath.Glob(filepath.Join(c.tmplDir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no *.tmpl templates found in template dir %s", c.tmplDir)
+		}
+		files = append(files, matches...)
+	}
+	
+	t, err := t.ParseFiles(files...)
 	if err != nil {
 		return nil, err
 	}
 	
-	return template.
-		New("template").
-		Funcs(funcMap).
-		Parse(string(b))
+	entry := c.entry
+	if entry == "" {
+		if len(c.tmpls) == 0 {
+			return nil, fmt.Errorf("an --entry template is required with --template-dir%s", t.DefinedTemplates())
+		}
+		entry = filepath.Base(c.tmpls[0])
+	}
+	
+	entryTmpl := t.Lookup(entry)
+	if entryTmpl == nil {
+		return nil, fmt.Errorf("entry template %q not found%s", entry, t.DefinedTemplates())
+	}
+	return entryTmpl, nil
 }
 
 func (c *cli) output(file string, w io.Writer) (io.Writer, func() error, error) {
@@ -124,7 +324,13 @@ func (c *cli) output(file string, w io.Writer) (io.Writer, func() error, error)
 		return w, closeFn, nil
 	}
 	
-	f, err := os.CreateTemp("", "")
+	// the temp file is renamed over the file, so it must be on the same
+	// filesystem and keep the mode of an existing file
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(file); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*.tmp")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -134,12 +340,20 @@ func (c *cli) output(file string, w io.Writer) (io.Writer, func() error, error)
 		if closed {
 			return nil
 		}
+		closed = true
 		if err := f.Close(); err != nil {
+			os.Remove(f.Name())
 			return err
 		}
-		err := os.Rename(f.Name(), file)
-		closed = true
-		return err
+		if err := os.Chmod(f.Name(), mode); err != nil {
+			os.Remove(f.Name())
+			return err
+		}
+		if err := os.Rename(f.Name(), file); err != nil {
+			os.Remove(f.Name())
+			return err
+		}
+		return nil
 	}
 	
 	return f, closeFn, nil
@@ -160,7 +374,10 @@ var funcMap = template.FuncMap{
 		s = nonwordRegex.ReplaceAllString(s, "")
 		return s
 	},
-	"statsHTMLTable": statsHTMLTable,
+	"json": func(v any) (string, error) {
+		b, err := json.MarshalIndent(v, "", "  ")
+		return string(b), err
+	},
 	"title": func(s string) string {
 		if s == "" {
 			return ""
@@ -176,32 +393,43 @@ var funcMap = template.FuncMap{
 	},
 }
 
-func newCount(s string) string {
-	return fmt.Sprintf(" **%s**", s)
-}
-
-func newColorSpan(color, s string) string {
-	return fmt.Sprintf(`<span style="color:%s">%s</span>`, color, s)
-}
-
 type input struct {
-	Commits commitSlc
+	Commits      commitSlc
+	Contributors []contributor
+	NextVersion  versionBump
+	Releases     []release
+	Unreleased   commitSlc
+	Vars         map[string]any
 }
 
 type commitSlc []commit
 
-func (c commitSlc) DropByField(field, value string) commitSlc {
-	matchFn := fieldsMatcherGen(field, false)
+// DropByField returns the commits whose field does not match the value. See
+// KeepByField for the fields and how they match.
+func (c commitSlc) DropByField(field, value string) (commitSlc, error) {
+	path, err := newFieldPath(field)
+	if err != nil {
+		return nil, err
+	}
 	return c.filter(func(c commit) bool {
-		return matchFn(c, value)
-	})
+		return !path.matches(c, value)
+	}), nil
 }
 
-func (c commitSlc) KeepByField(field, value string) commitSlc {
-	matchFn := fieldsMatcherGen(field, true)
+// KeepByField returns the commits whose field matches the value. The field is
+// any field of the commit or of its conventional commit, such as Type, or a
+// path of nested fields such as CC.Desc or Author.Email. Methods without
+// arguments, such as Subject, are fields too. Identities match
+// their name, email or "Name <email>", and list fields match when any of
+// their elements does.
+func (c commitSlc) KeepByField(field, value string) (commitSlc, error) {
+	path, err := newFieldPath(field)
+	if err != nil {
+		return nil, err
+	}
 	return c.filter(func(c commit) bool {
-		return matchFn(c, value)
-	})
+		return path.matches(c, value)
+	}), nil
 }
 
 func (c commitSlc) DropByNote(noteType, value string) commitSlc {
@@ -226,6 +454,40 @@ func (c commitSlc) KeepByNote(noteType, value string) commitSlc {
 	})
 }
 
+// Conventional returns the commits that are valid conventional commits.
+func (c commitSlc) Conventional() commitSlc {
+	return c.filter(func(c commit) bool {
+		return c.IsConventional
+	})
+}
+
+// NonConventional returns the commits that failed to parse as conventional
+// commits.
+func (c commitSlc) NonConventional() commitSlc {
+	return c.filter(func(c commit) bool {
+		return !c.IsConventional
+	})
+}
+
+// TouchingPath returns the commits that change a file matching the glob, or
+// a file in a directory matching the glob.
+func (c commitSlc) TouchingPath(glob string) (commitSlc, error) {
+	paths := pathFilter{glob}
+	if err := paths.validate(); err != nil {
+		return nil, err
+	}
+	return c.filter(func(c commit) bool {
+		return paths.touches(c.Files)
+	}), nil
+}
+
+// Breaking returns the commits that contain a breaking change.
+func (c commitSlc) Breaking() commitSlc {
+	return c.filter(func(c commit) bool {
+		return c.CC.IsBreaking
+	})
+}
+
 func (c commitSlc) filter(filterFn func(commit) bool) commitSlc {
 	var out commitSlc
 	for _, com := range c {
@@ -236,44 +498,48 @@ func (c commitSlc) filter(filterFn func(commit) bool) commitSlc {
 	return out
 }
 
-func fieldsMatcherGen(field string, keep bool) func(c commit, v string) bool {
-	cmpFn := func(a, b string) bool { return a == b }
-	if !keep {
-		cmpFn = func(a, b string) bool { return a != b }
-	}
-	return func(c commit, v string) bool {
-		
-		switch field {
-		case "Author":
-			return cmpFn(c.Author, v)
-		case "Scope":
-			return cmpFn(c.CC.Scope, v)
-		case "Type":
-			return cmpFn(c.CC.Type, v)
-		default:
-			return !keep
-		}
-	}
-}
-
 type (
 	commit struct {
-		Author    string
-		Hash      string
-		HashShort string
-		Message   string
-		Stats     string
-		CC        conventional
+		AckedBy        []identity
+		Author         identity
+		CoAuthors      []identity
+		Committer      identity
+		Files          fileStatSlc
+		Hash           string
+		HashShort      string
+		IsConventional bool
+		IsMerge        bool
+		Issues         []issueRef
+		Message        string
+		Parents        []string
+		ParseError     string
+		PullRequest    *issueRef
+		ReviewedBy     []identity
+		Signature      signature
+		SignedOffBy    []identity
+		Stats          string
+		TotalAdditions int
+		TotalDeletions int
+		CC             conventional
 	}
 	
 	conventional struct {
-		Body   string
-		Desc   string
-		Footer string
-		Header string
-		Notes  noteSlc
-		Scope  string
-		Type   string
+		Body                string
+		BreakingDescription string
+		Desc                string
+		Footer              string
+		Header              string
+		IsBreaking          bool
+		Notes               noteSlc
+		Scope               string
+		Type                string
+		TypeName            string
+	}
+	
+	identity struct {
+		Name  string
+		Email string
+		Time  time.Time
 	}
 	
 	note struct {
@@ -282,6 +548,65 @@ type (
 	}
 )
 
+var breakingNoteTypes = []string{"BREAKING CHANGE", "BREAKING-CHANGE"}
+
+// breakingDescription returns the values of the breaking change notes. When
+// the breaking change is only marked with a ! in the header, the description
+// of the commit describes the breaking change.
+func breakingDescription(desc string, notes noteSlc) string {
+	var descs []string
+	for _, n := range notes {
+		if slices.Contains(breakingNoteTypes, n.Type) {
+			descs = append(descs, n.Value)
+		}
+	}
+	if len(descs) == 0 {
+		return desc
+	}
+	return strings.Join(descs, "\n")
+}
+
+// Subject returns the first line of the commit message.
+func (c commit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return subject
+}
+
+func newIdentity(sig object.Signature) identity {
+	return identity{
+		Name:  sig.Name,
+		Email: sig.Email,
+		Time:  sig.When,
+	}
+}
+
+// String returns the identity in the git format of "Name <email>".
+func (i identity) String() string {
+	if i.Email == "" {
+		return i.Name
+	}
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+// IsBot is true for the identities of bots, whose name or email user ends
+// with [bot], such as dependabot[bot], or with -bot.
+func (i identity) IsBot() bool {
+	user, _, _ := strings.Cut(i.Email, "@")
+	for _, v := range []string{i.Name, user} {
+		v = strings.ToLower(v)
+		if strings.HasSuffix(v, "[bot]") || strings.HasSuffix(v, "-bot") {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the provided value is equal to the identity's
+// name, email, or the "Name <email>" form.
+func (i identity) Matches(v string) bool {
+	return v == i.Name || v == i.Email || v == i.String()
+}
+
 type noteSlc []note
 
 func (n noteSlc) KeepByType(nType string) noteSlc {
@@ -294,153 +619,220 @@ func (n noteSlc) KeepByType(nType string) noteSlc {
 	return out
 }
 
-func parseGitTemplVars(r *git.Repository) ([]commit, error) {
-	iter, err := r.Log(&git.LogOptions{})
+type parseOpts struct {
+	// from is the revision whose ancestors are excluded from the log. When
+	// empty, the log walks back to the root commit(s).
+	from string
+	// to is the revision the log starts from. Defaults to HEAD when empty.
+	to string
+	// typeNames maps conventional commit types to their display names.
+	typeNames map[string]string
+	// paths limits the commits to those touching any of the paths.
+	paths pathFilter
+	// stats computes the files changed by each of the commits, which diffs
+	// the tree of every commit.
+	stats bool
+	// jobs is the number of commits diffed concurrently.
+	jobs int
+	// cache is the cache of the parsed commits. When nil, every commit is
+	// parsed.
+	cache *commitCache
+	// merges limits the commits by their merges.
+	merges mergeOpts
+	// refs extracts the pull request and issues of the commits. When nil,
+	// no references are extracted.
+	refs *refParser
+	// mailmap maps the identities of the commits to the canonical ones. It
+	// is applied after the cache, so that changes to it apply to cached
+	// commits.
+	mailmap *mailmap
+	// keyring is the GPG keyring the signatures are verified against. When
+	// empty, no signature is verified.
+	keyring openpgp.EntityList
+}
+
+func parseGitTemplVars(r *git.Repository, opts parseOpts) ([]commit, error) {
+	if err := opts.paths.validate(); err != nil {
+		return nil, err
+	}
+	
+	iter, err := logRange(r, opts.from, opts.to, opts.merges.firstParent)
 	if err != nil {
 		return nil, err
 	}
 	
 	p := parser.New()
 	
-	var commits []commit
+	var (
+		commits  []commit
+		objs     []*object.Commit
+		hasFiles []bool
+	)
 	err = iter.ForEach(func(c *object.Commit) error {
-		com := commit{
-			Message: c.Message,
-			Hash:    c.Hash.String(),
-		}
-		if maxLen := 7; len(com.Hash) > maxLen {
-			com.HashShort = com.Hash[:maxLen]
+		if !opts.merges.include(c) {
+			return nil
 		}
 		
-		fi, err := c.Stats()
-		if err != nil {
-			return err
+		com, files, ok := opts.cache.get(c.Hash.String())
+		if !ok {
+			com = newCommit(p, c)
 		}
-		com.Stats = fi.String()
-		
-		if cc, err := p.Parse(c.Message); err == nil {
-			var notes []note
-			for _, n := range cc.Notes() {
-				notes = append(notes, note{
-					Type:  n.Token(),
-					Value: n.Value(),
-				})
-			}
-			
-			com.CC = conventional{
-				Body:   cc.Body(),
-				Desc:   cc.Description(),
-				Footer: cc.Footer(),
-				Header: cc.Header(),
-				Notes:  notes,
-				Scope:  cc.Scope(),
-				Type:   cc.Type(),
-			}
+		if com.IsConventional {
+			com.CC.TypeName = typeName(opts.typeNames, com.CC.Type)
 		}
+		com.PullRequest, com.Issues = opts.refs.parse(com.Message)
 		
 		commits = append(commits, com)
+		objs = append(objs, c)
+		hasFiles = append(hasFiles, files)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	
+	if opts.stats || len(opts.paths) > 0 {
+		var (
+			missing    []*object.Commit
+			missingIdx []int
+		)
+		for i := range commits {
+			if !hasFiles[i] {
+				missing = append(missing, objs[i])
+				missingIdx = append(missingIdx, i)
+			}
+		}
+		
+		files, err := commitsFiles(r, missing, opts.jobs)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range missingIdx {
+			commits[i].setFiles(files[j])
+			hasFiles[i] = true
+		}
+	}
 	
+	// only the commits touching the paths are verified, as verifying is
+	// far slower than parsing
+	kept := commits[:0]
+	for i, com := range commits {
+		opts.cache.put(com, hasFiles[i])
+		if !opts.paths.touches(com.Files) {
+			continue
+		}
+		com.applyMailmap(opts.mailmap)
+		com.verifySignature(objs[i], opts.keyring)
+		kept = append(kept, com)
+	}
+	commits = kept
 	slices.Reverse(commits)
-	return commits, err
+	return commits, nil
 }
 
-var (
-	statRegex      = regexp.MustCompile(`(?P<file>[\w.\-/]+[ =>]*[\w.\-/]+)\s*|\s*(?P<count>\d+)\s*(?P<additions>\+*)(?P<removals>-*)`)
-	statGroupNames = statRegex.SubexpNames()
-)
+// newCommit parses the commit. The files of the commit are not computed.
+func newCommit(p *parser.Parser, c *object.Commit) commit {
+	com := commit{
+		Author:    newIdentity(c.Author),
+		Committer: newIdentity(c.Committer),
+		Message:   c.Message,
+		Hash:      c.Hash.String(),
+		IsMerge:   c.NumParents() > 1,
+		Signature: newSignature(c.PGPSignature),
+	}
+	if maxLen := 7; len(com.Hash) > maxLen {
+		com.HashShort = com.Hash[:maxLen]
+	}
+	for _, h := range c.ParentHashes {
+		com.Parents = append(com.Parents, h.String())
+	}
+	
+	t := parseTrailers(c.Message)
+	com.CoAuthors, com.SignedOffBy, com.ReviewedBy, com.AckedBy = t.CoAuthors, t.SignedOffBy, t.ReviewedBy, t.AckedBy
+	
+	cc, err := parseConventional(p, c.Message)
+	if err != nil {
+		com.ParseError = err.Error()
+	} else {
+		com.CC, com.IsConventional = cc, true
+	}
+	return com
+}
 
-func statsHTMLTable(in string) string {
-	var (
-		sb         strings.Builder
-		line       string
-		firstWrite bool
-		
-		fileLenMax, countLenMax, diffLenMax int
-		diffLen                             int
-	)
-	writeLine := func(line string) {
-		if !firstWrite {
-			sb.WriteString(fmt.Sprintf(
-				"| File%s| Count%s| Diff%s|\n",
-				strings.Repeat(" ", max(0, fileLenMax-5)),
-				strings.Repeat(" ", max(0, countLenMax-6)),
-				strings.Repeat(" ", max(0, diffLenMax-5)),
-			))
-			sb.WriteString(fmt.Sprintf(
-				"|%s|%s|%s|\n",
-				strings.Repeat("-", fileLenMax),
-				strings.Repeat("-", countLenMax),
-				strings.Repeat("-", diffLenMax),
-			))
-			firstWrite = true
-		}
-		if !strings.HasSuffix(line, "|") {
-			line += strings.Repeat(" ", max(0, diffLenMax-diffLen-1))
-			line += "|"
-		}
-		diffLen = 0
-		sb.WriteString(line + "\n")
-	}
-	for _, matches := range statRegex.FindAllStringSubmatch(in, -1) {
-		for groupIdx, match := range matches {
-			if groupIdx == 0 || match == "" {
-				continue
-			}
-			switch statGroupNames[groupIdx] {
-			case "file":
-				diffLenMax, diffLen = max(diffLenMax, diffLen+2), 0
-				fileLenMax = max(fileLenMax, len(newFileLink(match))+1)
-			case "count":
-				countLenMax = max(countLenMax, len(newCount(match))+1)
-			case "additions":
-				diffLen = len(newColorSpan("green", match))
-			case "removals":
-				diffLen += len(newColorSpan("red", match))
-			}
-		}
+func (c *commit) setFiles(files fileStatSlc) {
+	c.Files = files
+	c.Stats = files.String()
+	c.TotalAdditions, c.TotalDeletions = files.totals()
+}
+
+// logRange returns an iterator of the commits reachable from the to revision
+// that are not reachable from the from revision, the same as git log from..to.
+// With firstParent, only the first parent of merge commits is followed.
+func logRange(r *git.Repository, from, to string, firstParent bool) (object.CommitIter, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	toHash, err := r.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve to revision %q: %w", to, err)
 	}
-	diffLenMax = max(diffLenMax, diffLen+2)
 	
-	diffLen = 0
-	for _, matches := range statRegex.FindAllStringSubmatch(in, -1) {
-		for groupIdx, match := range matches {
-			if groupIdx == 0 || match == "" {
-				continue
-			}
-			switch statGroupNames[groupIdx] {
-			case "file":
-				if line != "" {
-					writeLine(line)
-				}
-				link := newFileLink(match)
-				line = fmt.Sprintf(`|%s%s`, link, strings.Repeat(" ", max(0, fileLenMax-len(link))))
-			case "count":
-				count := newCount(match)
-				line += fmt.Sprintf(`|%s%s| `, count, strings.Repeat(" ", max(0, countLenMax-len(count))))
-			case "additions":
-				adds := newColorSpan("green", match)
-				diffLen = len(adds)
-				line += adds
-			case "removals":
-				removals := newColorSpan("red", match)
-				diffLen += len(removals)
-				line += removals
-			}
+	excluded := make(map[plumbing.Hash]bool)
+	if from != "" {
+		fromHash, err := r.ResolveRevision(plumbing.Revision(from))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve from revision %q: %w", from, err)
+		}
+		fromIter, err := r.Log(&git.LogOptions{From: *fromHash})
+		if err != nil {
+			return nil, err
+		}
+		err = fromIter.ForEach(func(c *object.Commit) error {
+			excluded[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
-	if line != "" {
-		writeLine(line)
+	
+	toCommit, err := r.CommitObject(*toHash)
+	if err != nil {
+		return nil, err
+	}
+	if firstParent {
+		return &firstParentIter{next: toCommit, excluded: excluded}, nil
 	}
-	return sb.String()
+	return object.NewCommitPreorderIter(toCommit, excluded, nil), nil
 }
 
-func newFileLink(s string) string {
-	parts := strings.SplitN(s, " => ", 2)
-	dest := parts[0]
-	if len(parts) == 2 {
-		dest = parts[1]
+func parseConventional(p *parser.Parser, msg string) (conventional, error) {
+	cc, err := p.Parse(msg)
+	if err != nil {
+		return conventional{}, err
 	}
-	return fmt.Sprintf(" [%s](%s)", s, dest)
+	
+	var notes []note
+	for _, n := range cc.Notes() {
+		notes = append(notes, note{
+			Type:  n.Token(),
+			Value: n.Value(),
+		})
+	}
+	
+	out := conventional{
+		Body:       cc.Body(),
+		Desc:       cc.Description(),
+		Footer:     cc.Footer(),
+		Header:     cc.Header(),
+		IsBreaking: cc.IsBreakingChange(),
+		Notes:      notes,
+		Scope:      cc.Scope(),
+		Type:       cc.Type(),
+	}
+	if out.IsBreaking {
+		out.BreakingDescription = breakingDescription(out.Desc, out.Notes)
+	}
+	
+	return out, nil
 }
@@ -2,8 +2,16 @@ package main
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+	
+	"github.com/conventionalcommit/parser"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 func TestCmd(t *testing.T) {
@@ -19,7 +27,7 @@ func TestCmd(t *testing.T) {
 `)
 	cmd.SetIn(tmplIn)
 	
-	cmd.SetArgs([]string{"--dir", "../testtmpldir"})
+	cmd.SetArgs([]string{"--dir", "../testtmpldir", "--no-cache"})
 	
 	err := cmd.Execute()
 	if err != nil {
@@ -29,6 +37,202 @@ func TestCmd(t *testing.T) {
 	t.Log(buf.String())
 }
 
+func TestCmdTemplates(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", nil)
+	tr.commit("fix: second", nil)
+	
+	tmplDir := t.TempDir()
+	writeFile(t, filepath.Join(tmplDir, "partials.tmpl"), `{{ define "header" }}# Changes{{ end }}
+{{- define "commit-row" }}
+* {{ .CC.Desc }}
+{{- end }}`)
+	writeFile(t, filepath.Join(tmplDir, "changelog.tmpl"), `{{ template "header" }}
+{{ range .Commits }}{{ template "commit-row" . }}{{ end }}`)
+	writeFile(t, filepath.Join(tmplDir, "ignored.txt"), `{{ template "missing" }}`)
+	
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "with template dir and entry file",
+			args: []string{"--template-dir", tmplDir, "--entry", "changelog.tmpl"},
+			want: "# Changes\n\n* first\n* second",
+		},
+		{
+			name: "with template dir and entry defined template",
+			args: []string{"--template-dir", tmplDir, "--entry", "header"},
+			want: "# Changes",
+		},
+		{
+			name: "with multiple templates should execute first",
+			args: []string{
+				"-t", filepath.Join(tmplDir, "changelog.tmpl"),
+				"-t", filepath.Join(tmplDir, "partials.tmpl"),
+			},
+			want: "# Changes\n\n* first\n* second",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCmd()
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetArgs(append([]string{"--dir", tr.dir}, tt.args...))
+			
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err.Error())
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected output:\n\twant: %q\n\tgot: %q", tt.want, got)
+			}
+		})
+	}
+	
+	errTests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "with template dir and without entry should error",
+			args:    []string{"--template-dir", tmplDir},
+			wantErr: "an --entry template is required with --template-dir",
+		},
+		{
+			name:    "with unknown entry should error",
+			args:    []string{"--template-dir", tmplDir, "--entry", "rando"},
+			wantErr: `entry template "rando" not found`,
+		},
+		{
+			name:    "with empty template dir should error",
+			args:    []string{"--template-dir", t.TempDir(), "--entry", "changelog.tmpl"},
+			wantErr: "no *.tmpl templates found",
+		},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCmd()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{"--dir", tr.dir}, tt.args...))
+			
+			err := cmd.Execute()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("unexpected error:\n\twant: %s\n\tgot: %s", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseGitTemplVars(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", map[string]string{"a.txt": "a"})
+	second := tr.commit("feat: second", map[string]string{"b.txt": "b"})
+	tr.tag("v0.1.0", second, "")
+	third := tr.commit("fix: third", map[string]string{"a.txt": "aa"})
+	tr.tag("v0.1.1", third, "release v0.1.1")
+	tr.commit("chore: fourth", map[string]string{"c.txt": "c"})
+	
+	tests := []struct {
+		name string
+		opts parseOpts
+		want []string
+	}{
+		{
+			name: "without range should return entire history",
+			want: []string{"feat: first", "feat: second", "fix: third", "chore: fourth"},
+		},
+		{
+			name: "with from tag should return commits after tag",
+			opts: parseOpts{from: "v0.1.0"},
+			want: []string{"fix: third", "chore: fourth"},
+		},
+		{
+			name: "with from and annotated to tag should return range",
+			opts: parseOpts{from: "v0.1.0", to: "v0.1.1"},
+			want: []string{"fix: third"},
+		},
+		{
+			name: "with to relative revision should return history up to it",
+			opts: parseOpts{to: "HEAD~2"},
+			want: []string{"feat: first", "feat: second"},
+		},
+		{
+			name: "with from hash equal to to should return nothing",
+			opts: parseOpts{from: third.String(), to: "v0.1.1"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGitTemplVars(tr.repo, tt.opts)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			
+			mustLen(t, got, len(tt.want))
+			for i, want := range tt.want {
+				if got := strings.TrimSpace(got[i].Message); got != want {
+					t.Errorf("unexpected message at %d:\n\twant: %s\n\tgot: %s", i, want, got)
+				}
+			}
+		})
+	}
+	
+	t.Run("should populate author and committer", func(t *testing.T) {
+		got, err := parseGitTemplVars(tr.repo, parseOpts{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		mustLen(t, got, 4)
+		first := got[0]
+		for _, ident := range []identity{first.Author, first.Committer} {
+			if want := "Jane Doe <jane@example.com>"; ident.String() != want {
+				t.Errorf("unexpected identity:\n\twant: %s\n\tgot: %s", want, ident.String())
+			}
+			if want := tr.when.Add(-3 * time.Minute); !ident.Time.Equal(want) {
+				t.Errorf("unexpected identity time:\n\twant: %s\n\tgot: %s", want, ident.Time)
+			}
+		}
+	})
+	
+	t.Run("should expose conventional parse status", func(t *testing.T) {
+		tr := newTestRepo(t)
+		tr.commit("feat: first", nil)
+		tr.commit("not conventional", nil)
+		
+		got, err := parseGitTemplVars(tr.repo, parseOpts{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		mustLen(t, got, 2)
+		if !got[0].IsConventional || got[0].ParseError != "" {
+			t.Errorf("expected conventional commit: %#v", got[0])
+		}
+		if got[1].IsConventional {
+			t.Error("expected non conventional commit")
+		}
+		if want := "type: invalid character ' '"; got[1].ParseError != want {
+			t.Errorf("unexpected parse error:\n\twant: %s\n\tgot: %s", want, got[1].ParseError)
+		}
+	})
+	
+	t.Run("with unknown revision should error", func(t *testing.T) {
+		_, err := parseGitTemplVars(tr.repo, parseOpts{from: "v9.9.9"})
+		if err == nil {
+			t.Fatal("expected error for unknown revision")
+		}
+	})
+}
+
 func TestCommitSlc(t *testing.T) {
 	type inputs struct {
 		Field string
@@ -37,9 +241,13 @@ func TestCommitSlc(t *testing.T) {
 	
 	newCommit := func(id, cType string, notes ...note) commit {
 		return commit{
-			Author:  "author-" + id,
+			Author: identity{
+				Name:  "author-" + id,
+				Email: "author-" + id + "@example.com",
+			},
 			Message: "message-" + id,
 			CC: conventional{
+				Desc:  "desc-" + id,
 				Notes: notes,
 				Scope: "scope-" + id,
 				Type:  cType,
@@ -68,6 +276,22 @@ func TestCommitSlc(t *testing.T) {
 				},
 				want: []commit{commit2},
 			},
+			{
+				name: "by matching author email should pass",
+				input: inputs{
+					Field: "Author",
+					Value: "author-2@example.com",
+				},
+				want: []commit{commit2},
+			},
+			{
+				name: "by matching author name and email should pass",
+				input: inputs{
+					Field: "Author",
+					Value: "author-2 <author-2@example.com>",
+				},
+				want: []commit{commit2},
+			},
 			{
 				name: "by matching scope should pass",
 				input: inputs{
@@ -84,6 +308,46 @@ func TestCommitSlc(t *testing.T) {
 				},
 				want: []commit{commit1, commitWithNotes1},
 			},
+			{
+				name: "by matching conventional field should pass",
+				input: inputs{
+					Field: "Desc",
+					Value: "desc-1",
+				},
+				want: []commit{commit1},
+			},
+			{
+				name: "by matching nested field should pass",
+				input: inputs{
+					Field: "CC.Desc",
+					Value: "desc-3",
+				},
+				want: []commit{commit3},
+			},
+			{
+				name: "by matching nested identity field should pass",
+				input: inputs{
+					Field: "Author.Email",
+					Value: "author-2@example.com",
+				},
+				want: []commit{commit2},
+			},
+			{
+				name: "by matching bool field should pass",
+				input: inputs{
+					Field: "CC.IsBreaking",
+					Value: "false",
+				},
+				want: []commit{commit1, commit2, commit3, commitWithNotes1, commitWithNotes2},
+			},
+			{
+				name: "by matching any element of list field should pass",
+				input: inputs{
+					Field: "CC.Notes.Type",
+					Value: "baz",
+				},
+				want: []commit{commitWithNotes2},
+			},
 			{
 				name: "without matching value should return empty slice",
 				input: inputs{
@@ -95,7 +359,10 @@ func TestCommitSlc(t *testing.T) {
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				got := commits.KeepByField(tt.input.Field, tt.input.Value)
+				got, err := commits.KeepByField(tt.input.Field, tt.input.Value)
+				if err != nil {
+					t.Fatal(err.Error())
+				}
 				mustLen(t, got, len(tt.want))
 				for i, want := range tt.want {
 					commitEq(t, want, got[i])
@@ -134,6 +401,14 @@ func TestCommitSlc(t *testing.T) {
 				},
 				want: []commit{commit2, commit3, commitWithNotes2},
 			},
+			{
+				name: "by matching nested field should drop",
+				input: inputs{
+					Field: "CC.Notes.Value",
+					Value: "bar",
+				},
+				want: []commit{commit1, commit2, commit3, commitWithNotes2},
+			},
 			{
 				name: "without matching value should return empty slice",
 				input: inputs{
@@ -145,7 +420,10 @@ func TestCommitSlc(t *testing.T) {
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				got := commits.DropByField(tt.input.Field, tt.input.Value)
+				got, err := commits.DropByField(tt.input.Field, tt.input.Value)
+				if err != nil {
+					t.Fatal(err.Error())
+				}
 				mustLen(t, got, len(tt.want))
 				for i, want := range tt.want {
 					commitEq(t, want, got[i])
@@ -154,6 +432,17 @@ func TestCommitSlc(t *testing.T) {
 		}
 	})
 	
+	t.Run("ByField with unknown field should error", func(t *testing.T) {
+		for _, field := range []string{"Rando", "CC.Rando", "Author.Rando", "CC.Desc.Rando", "cc.Desc", "Rando.Type"} {
+			if _, err := commits.KeepByField(field, "v"); err == nil {
+				t.Errorf("expected KeepByField error for field %q", field)
+			}
+			if _, err := commits.DropByField(field, "v"); err == nil {
+				t.Errorf("expected DropByField error for field %q", field)
+			}
+		}
+	})
+	
 	t.Run("KeepByNote", func(t *testing.T) {
 		tests := []struct {
 			name  string
@@ -204,6 +493,31 @@ func TestCommitSlc(t *testing.T) {
 		}
 	})
 	
+	t.Run("Breaking", func(t *testing.T) {
+		breaking := newCommit("6", "feat")
+		breaking.CC.IsBreaking = true
+		
+		got := append(commits, breaking).Breaking()
+		mustLen(t, got, 1)
+		commitEq(t, breaking, got[0])
+	})
+	
+	t.Run("Conventional", func(t *testing.T) {
+		nonConventional := commit{Message: "message-6", ParseError: "type: invalid character ' '"}
+		conventional := newCommit("7", "feat")
+		conventional.IsConventional = true
+		
+		all := append(commits, nonConventional, conventional)
+		
+		got := all.Conventional()
+		mustLen(t, got, 1)
+		commitEq(t, conventional, got[0])
+		
+		got = all.NonConventional()
+		mustLen(t, got, len(commits)+1)
+		commitEq(t, nonConventional, got[len(got)-1])
+	})
+	
 	t.Run("DropByNote", func(t *testing.T) {
 		tests := []struct {
 			name  string
@@ -255,6 +569,58 @@ func TestCommitSlc(t *testing.T) {
 	})
 }
 
+func TestParseConventional(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		breaking bool
+		desc     string
+	}{
+		{
+			name: "without breaking change",
+			msg:  "feat(api): add endpoint",
+		},
+		{
+			name:     "with ! marker should use description",
+			msg:      "feat(api)!: drop v1 endpoints",
+			breaking: true,
+			desc:     "drop v1 endpoints",
+		},
+		{
+			name:     "with BREAKING CHANGE footer",
+			msg:      "fix: rename flag\n\nBREAKING CHANGE: --foo is now --bar",
+			breaking: true,
+			desc:     "--foo is now --bar",
+		},
+		{
+			name:     "with BREAKING-CHANGE footer",
+			msg:      "fix: rename flag\n\nBREAKING-CHANGE: --foo is now --bar",
+			breaking: true,
+			desc:     "--foo is now --bar",
+		},
+		{
+			name:     "with ! marker and footer should use footer",
+			msg:      "fix!: rename flag\n\nBREAKING CHANGE: --foo is now --bar\nRefs: #12",
+			breaking: true,
+			desc:     "--foo is now --bar",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConventional(parser.New(), tt.msg)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got.IsBreaking != tt.breaking {
+				t.Errorf("IsBreaking = %t, want %t", got.IsBreaking, tt.breaking)
+			}
+			if got.BreakingDescription != tt.desc {
+				t.Errorf("BreakingDescription do not match:\n\twant: %s\n\tgot: %s", tt.desc, got.BreakingDescription)
+			}
+		})
+	}
+}
+
 func TestNoteSlc_KeepByType(t *testing.T) {
 	note1 := note{Type: "foo", Value: "bar"}
 	note2 := note{Type: "foo", Value: "baz"}
@@ -272,82 +638,10 @@ func TestNoteSlc_KeepByType(t *testing.T) {
 	notesEq(t, []note{note5}, got)
 }
 
-func Test_statsHTML(t *testing.T) {
-	raw := `
- wild-workouts/.gitignore                                             |   36 ++++++++++++++++++++++++++++++++++++
- wild-workouts/LICENSE                                                |   21 +++++++++++++++++++++-----
- wild-workouts/Makefile                                               |   48 ++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/README.md                                              |  107 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/doc.go                                                 |   10 ++++++++++
- wild-workouts/internal/common/auth/http.go                           |   84 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/auth/http_mock.go                      |   44 ++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/auth.go                         |   41 +++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/grpc.go                         |   81 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/net.go                          |   37 +++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/trainer/openapi_client_gen.go   |  568 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/trainer/openapi_types.gen.go    |   57 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/trainings/openapi_client_gen.go | 1004 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/trainings/openapi_types.gen.go  |   60 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/users/openapi_client_gen.go     |  234 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/client/users/openapi_types.gen.go      |   15 +++++++++++++++
- wild-workouts/internal/common/decorator/command.go                   |   27 +++++++++++++++++++++++++++
- wild-workouts/internal/common/decorator/logging.go                   |   56 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/decorator/metrics.go                   |   62 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/decorator/query.go                     |   21 +++++++++++++++++++++
- wild-workouts/internal/common/errors/errors.go                       |   53 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/genproto/trainer/trainer.pb.go         |  315 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/genproto/trainer/trainer_grpc.pb.go    |  209 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/genproto/users/users.pb.go             |  305 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/genproto/users/users_grpc.pb.go        |  137 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/go.mod                                 |   54 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/go.sum                                 |  627 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/logs/cqrs.go                           |   15 +++++++++++++++
- wild-workouts/internal/common/logs/http.go                           |   64 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/logs/logrus.go                         |   31 +++++++++++++++++++++++++++++++
- wild-workouts/internal/common/metrics/dummy.go                       |    7 +++++++
- wild-workouts/internal/common/server/grpc.go                         |   54 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/server/http.go                         |   96 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/server/httperr/http_error.go           |   57 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/tests/clients.go                       |  174 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/tests/e2e_test.go                      |   69 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/tests/hours.go                         |   15 +++++++++++++++
- wild-workouts/internal/common/tests/jwt.go                           |   35 +++++++++++++++++++++++++++++++++++
- wild-workouts/internal/common/tests/wait.go                          |   33 +++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/adapters/hour_firestore_repository.go |  222 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/adapters/hour_memory_repository.go    |   69 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/adapters/hour_mysql_repository.go     |  198 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/adapters/hour_repository_test.go      |  350 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/app/app.go                            |   23 +++++++++++++++++++++++
- wild-workouts/internal/trainer/app/command/cancel_training.go        |   50 ++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/app/command/make_hours_available.go   |   52 ++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/app/command/make_hours_unavailable.go |   52 ++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/app/command/schedule_training.go      |   50 ++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/app/query/hour_availability.go        |   60 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/app/query/types.go                    |    2 ++
- wild-workouts/internal/trainer/domain/hour/availability.go           |   97 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/domain/hour/availability_test.go      |  125 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/domain/hour/hour.go                   |  221 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/domain/hour/hour_test.go              |  248 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/domain/hour/repository.go             |   15 +++++++++++++++
- wild-workouts/internal/trainer/fixtures.go                           |   99 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/go.mod                                |   62 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/go.sum                                |  628 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/main.go                               |   45 +++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/ports/grpc.go                         |   68 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/ports/http.go                         |   49 +++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/ports/openapi_api.gen.go              |  161 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/ports/openapi_types.gen.go            |   57 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/service/application.go                |   51 +++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/internal/trainer/service/component_test.go             |  108 +++++++++++++++++++++++++++++++++++++++++++++++++++++
- wild-workouts/sql/schema.sql                                         |    6 ++++++`
-	
-	t.Log(statsHTMLTable(raw))
-}
-
 func commitEq(t *testing.T, want, got commit) {
 	t.Helper()
 	
-	if want.Author != got.Author {
+	if want.Author.String() != got.Author.String() {
 		t.Errorf("Author do no tmatch:\n\twant: %s\n\tgot: %s", want.Author, got.Author)
 	}
 	if want.Hash != got.Hash {
@@ -384,3 +678,127 @@ func mustLen[T any](t *testing.T, slc []T, want int) {
 		t.Fatalf("len(slc) = %d, want %d\n\tgot: %v", len(slc), want, slc)
 	}
 }
+
+type testRepo struct {
+	t    *testing.T
+	dir  string
+	repo *git.Repository
+	when time.Time
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	
+	return &testRepo{
+		t:    t,
+		dir:  dir,
+		repo: r,
+		when: time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// renderTemplate renders the template read from stdin by the command run in
+// the repo of the dir with the args, and returns the output.
+func renderTemplate(t *testing.T, dir, tmpl string, args ...string) (string, error) {
+	t.Helper()
+	
+	cmd := newCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader(tmpl))
+	cmd.SetArgs(append([]string{"--dir", dir}, args...))
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func (tr *testRepo) commit(msg string, files map[string]string) plumbing.Hash {
+	tr.t.Helper()
+	
+	wt, err := tr.repo.Worktree()
+	if err != nil {
+		tr.t.Fatal(err.Error())
+	}
+	for name, content := range files {
+		path := filepath.Join(tr.dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tr.t.Fatal(err.Error())
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			tr.t.Fatal(err.Error())
+		}
+		if _, err := wt.Add(name); err != nil {
+			tr.t.Fatal(err.Error())
+		}
+	}
+	
+	tr.when = tr.when.Add(time.Minute)
+	h, err := wt.Commit(msg, &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            tr.signature(),
+	})
+	if err != nil {
+		tr.t.Fatal(err.Error())
+	}
+	return h
+}
+
+// merge commits the worktree with the parents, the first parent first.
+func (tr *testRepo) merge(msg string, parents ...plumbing.Hash) plumbing.Hash {
+	tr.t.Helper()
+	
+	wt, err := tr.repo.Worktree()
+	if err != nil {
+		tr.t.Fatal(err.Error())
+	}
+	
+	tr.when = tr.when.Add(time.Minute)
+	h, err := wt.Commit(msg, &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            tr.signature(),
+		Parents:           parents,
+	})
+	if err != nil {
+		tr.t.Fatal(err.Error())
+	}
+	return h
+}
+
+// reset moves the current branch and worktree to the commit.
+func (tr *testRepo) reset(h plumbing.Hash) {
+	tr.t.Helper()
+	
+	wt, err := tr.repo.Worktree()
+	if err != nil {
+		tr.t.Fatal(err.Error())
+	}
+	if err := wt.Reset(&git.ResetOptions{Commit: h, Mode: git.HardReset}); err != nil {
+		tr.t.Fatal(err.Error())
+	}
+}
+
+func (tr *testRepo) tag(name string, h plumbing.Hash, msg string) {
+	tr.t.Helper()
+	
+	var opts *git.CreateTagOptions
+	if msg != "" {
+		opts = &git.CreateTagOptions{Message: msg, Tagger: tr.signature()}
+	}
+	if _, err := tr.repo.CreateTag(name, h, opts); err != nil {
+		tr.t.Fatal(err.Error())
+	}
+}
+
+func (tr *testRepo) signature() *object.Signature {
+	return &object.Signature{
+		Name:  "Jane Doe",
+		Email: "jane@example.com",
+		When:  tr.when,
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// patternPrefixes are the prefixes of the kinds of patterns, a pattern
+// without any of them is a glob.
+const (
+	patternRegexp           = "re:"
+	patternRegexpIgnoreCase = "ire:"
+	patternGlob             = "glob:"
+	patternGlobIgnoreCase   = "iglob:"
+)
+
+// patternCache caches the compiled patterns, so that templates matching
+// within a range only compile each pattern once.
+var patternCache sync.Map
+
+// compilePattern compiles the pattern, which is one of:
+//
+//	re:REGEXP     regular expression matching any part of the value
+//	ire:REGEXP    case-insensitive regular expression
+//	glob:GLOB     glob matching the entire value, the default without a prefix
+//	iglob:GLOB    case-insensitive glob
+//
+// The globs support the * (any characters), ? (any character) and [...]
+// (character class) wildcards.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	
+	var expr string
+	switch {
+	case strings.HasPrefix(pattern, patternRegexp):
+		expr = strings.TrimPrefix(pattern, patternRegexp)
+	case strings.HasPrefix(pattern, patternRegexpIgnoreCase):
+		expr = "(?i)" + strings.TrimPrefix(pattern, patternRegexpIgnoreCase)
+	case strings.HasPrefix(pattern, patternGlob):
+		expr = globRegexp(strings.TrimPrefix(pattern, patternGlob))
+	case strings.HasPrefix(pattern, patternGlobIgnoreCase):
+		expr = "(?i)" + globRegexp(strings.TrimPrefix(pattern, patternGlobIgnoreCase))
+	default:
+		expr = globRegexp(pattern)
+	}
+	
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+// globRegexp returns the anchored regular expression of the glob, whose
+// wildcards match newlines too.
+func globRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	for i, size := 0, 0; i < len(glob); i += size {
+		var c rune
+		c, size = utf8.DecodeRuneInString(glob[i:])
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if rest, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + rest
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			size = end + 2
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// matchesPattern reports whether the field of the commit matches the pattern.
+// Identities match when their name, email or "Name <email>" does.
+func (p fieldPath) matchesPattern(c commit, re *regexp.Regexp) bool {
+	return p.matchesValue(reflect.ValueOf(c), func(v reflect.Value) bool {
+		switch x := v.Interface().(type) {
+		case identity:
+			return re.MatchString(x.Name) || re.MatchString(x.Email) || re.MatchString(x.String())
+		default:
+			return re.MatchString(fmt.Sprint(x))
+		}
+	})
+}
+
+// KeepByFieldMatch returns the commits whose field matches the pattern. See
+// KeepByField for the fields, and compilePattern for the patterns.
+func (c commitSlc) KeepByFieldMatch(field, pattern string) (commitSlc, error) {
+	path, re, err := fieldPattern(field, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return c.filter(func(c commit) bool {
+		return path.matchesPattern(c, re)
+	}), nil
+}
+
+// DropByFieldMatch returns the commits whose field does not match the
+// pattern.
+func (c commitSlc) DropByFieldMatch(field, pattern string) (commitSlc, error) {
+	path, re, err := fieldPattern(field, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return c.filter(func(c commit) bool {
+		return !path.matchesPattern(c, re)
+	}), nil
+}
+
+func fieldPattern(field, pattern string) (fieldPath, *regexp.Regexp, error) {
+	path, err := newFieldPath(field)
+	if err != nil {
+		return nil, nil, err
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	return path, re, nil
+}
+
+// KeepByNoteMatch returns the commits with a note whose type and value match
+// the patterns.
+func (c commitSlc) KeepByNoteMatch(typePattern, valuePattern string) (commitSlc, error) {
+	typeRe, err := compilePattern(typePattern)
+	if err != nil {
+		return nil, err
+	}
+	valueRe, err := compilePattern(valuePattern)
+	if err != nil {
+		return nil, err
+	}
+	return c.filter(func(c commit) bool {
+		for _, n := range c.CC.Notes {
+			if typeRe.MatchString(n.Type) && valueRe.MatchString(n.Value) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// KeepByTypeMatch returns the notes whose type matches the pattern.
+func (n noteSlc) KeepByTypeMatch(pattern string) (noteSlc, error) {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var out noteSlc
+	for _, note := range n {
+		if re.MatchString(note.Type) {
+			out = append(out, note)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{pattern: "feat", value: "feat", want: true},
+		{pattern: "feat", value: "feature"},
+		{pattern: "feat", value: "FEAT"},
+		{pattern: "api*", value: "api/v2", want: true},
+		{pattern: "glob:api*", value: "web"},
+		{pattern: "glob:v?.[0-9]", value: "v1.2", want: true},
+		{pattern: "glob:[!a]pi", value: "api"},
+		{pattern: "glob:a.c", value: "abc"},
+		{pattern: "glob:fix*", value: "fix: thing\n\nbody", want: true},
+		{pattern: "iglob:FEAT", value: "feat", want: true},
+		{pattern: "café*", value: "café au lait", want: true},
+		{pattern: "caf?", value: "café", want: true},
+		{pattern: "iglob:ÄPI*", value: "äpi-x", want: true},
+		{pattern: "glob:[äö]pi", value: "öpi", want: true},
+		{pattern: `re:CVE-\d+`, value: "patch CVE-2024-1234 in parser", want: true},
+		{pattern: `re:^cve`, value: "CVE-2024-1234"},
+		{pattern: `ire:^cve`, value: "CVE-2024-1234", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.value, func(t *testing.T) {
+			re, err := compilePattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got := re.MatchString(tt.value); got != tt.want {
+				t.Errorf("unexpected match of %q:\n\twant: %t\n\tgot: %t", tt.value, tt.want, got)
+			}
+		})
+	}
+	
+	t.Run("should be cached", func(t *testing.T) {
+		re1, err := compilePattern("ire:cached")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		re2, err := compilePattern("ire:cached")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if re1 != re2 {
+			t.Error("expected the same compiled pattern")
+		}
+	})
+	
+	t.Run("with invalid regexp should error", func(t *testing.T) {
+		if _, err := compilePattern("re:("); err == nil {
+			t.Fatal("expected error for invalid regexp")
+		}
+	})
+}
+
+func TestCommitSlc_Match(t *testing.T) {
+	commits := commitSlc{
+		{
+			Author: identity{Name: "Jane Doe", Email: "jane@example.com"},
+			CC:     conventional{Type: "feat", Scope: "api", Desc: "add endpoint"},
+		},
+		{
+			Author: identity{Name: "dependabot[bot]", Email: "bot@example.com"},
+			CC:     conventional{Type: "Fix", Scope: "api-client", Desc: "bump parser for CVE-2024-1234"},
+		},
+		{
+			Author: identity{Name: "John Smith", Email: "john@example.com"},
+			CC: conventional{
+				Type:  "fix",
+				Scope: "web",
+				Desc:  "escape input",
+				Notes: noteSlc{{Type: "Security", Value: "fixes XSS"}, {Type: "Refs", Value: "#12"}},
+			},
+		},
+	}
+	
+	tests := []struct {
+		name    string
+		fn      func() (commitSlc, error)
+		wantIdx []int
+	}{
+		{
+			name:    "keep scopes starting with api",
+			fn:      func() (commitSlc, error) { return commits.KeepByFieldMatch("Scope", "api*") },
+			wantIdx: []int{0, 1},
+		},
+		{
+			name:    "keep descriptions mentioning a CVE",
+			fn:      func() (commitSlc, error) { return commits.KeepByFieldMatch("CC.Desc", `re:CVE-\d{4}-\d+`) },
+			wantIdx: []int{1},
+		},
+		{
+			name:    "keep types ignoring case",
+			fn:      func() (commitSlc, error) { return commits.KeepByFieldMatch("Type", "iglob:fix") },
+			wantIdx: []int{1, 2},
+		},
+		{
+			name:    "drop bot authors",
+			fn:      func() (commitSlc, error) { return commits.DropByFieldMatch("Author", `re:\[bot\]$`) },
+			wantIdx: []int{0, 2},
+		},
+		{
+			name:    "keep notes by type and value",
+			fn:      func() (commitSlc, error) { return commits.KeepByNoteMatch("iglob:security", "re:XSS") },
+			wantIdx: []int{2},
+		},
+		{
+			name:    "keep notes without matching value",
+			fn:      func() (commitSlc, error) { return commits.KeepByNoteMatch("Security", "re:CSRF") },
+			wantIdx: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			mustLen(t, got, len(tt.wantIdx))
+			for i, idx := range tt.wantIdx {
+				commitEq(t, commits[idx], got[i])
+			}
+		})
+	}
+	
+	t.Run("with unknown field should error", func(t *testing.T) {
+		if _, err := commits.KeepByFieldMatch("Rando", "*"); err == nil {
+			t.Fatal("expected error for unknown field")
+		}
+	})
+	
+	t.Run("with invalid pattern should error", func(t *testing.T) {
+		if _, err := commits.DropByFieldMatch("Type", "re:["); err == nil {
+			t.Fatal("expected error for invalid pattern")
+		}
+	})
+}
+
+func TestNoteSlc_KeepByTypeMatch(t *testing.T) {
+	notes := noteSlc{
+		{Type: "BREAKING CHANGE", Value: "a"},
+		{Type: "BREAKING-CHANGE", Value: "b"},
+		{Type: "Refs", Value: "c"},
+	}
+	
+	got, err := notes.KeepByTypeMatch("iglob:breaking?change")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	notesEq(t, notes[:2], got)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"io"
+	
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/spf13/cobra"
+)
+
+// mergeOpts limit the commits by their merges, the same as the git log flags
+// of the same name.
+type mergeOpts struct {
+	// firstParent only follows the first parent of merge commits, omitting
+	// the commits of merged branches.
+	firstParent bool
+	// noMerges omits the merge commits.
+	noMerges bool
+	// mergesOnly omits the commits that are not merge commits.
+	mergesOnly bool
+}
+
+func (c *cli) registerMergeFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&c.merges.firstParent, "first-parent", false, "only follow the first parent of merge commits, omitting the commits of merged branches")
+	cmd.Flags().BoolVar(&c.merges.noMerges, "no-merges", false, "omit merge commits")
+	cmd.Flags().BoolVar(&c.merges.mergesOnly, "merges-only", false, "only include merge commits")
+	cmd.MarkFlagsMutuallyExclusive("no-merges", "merges-only")
+}
+
+// include reports whether the commit is included by the merge options.
+func (m mergeOpts) include(c *object.Commit) bool {
+	isMerge := c.NumParents() > 1
+	switch {
+	case m.noMerges:
+		return !isMerge
+	case m.mergesOnly:
+		return isMerge
+	default:
+		return true
+	}
+}
+
+// firstParentIter iterates the commits from the tip by following only the
+// first parent, stopping at the first excluded commit.
+type firstParentIter struct {
+	next     *object.Commit
+	excluded map[plumbing.Hash]bool
+}
+
+func (it *firstParentIter) Next() (*object.Commit, error) {
+	c := it.next
+	if c == nil || it.excluded[c.Hash] {
+		return nil, io.EOF
+	}
+	
+	it.next = nil
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		it.next = parent
+	}
+	return c, nil
+}
+
+func (it *firstParentIter) ForEach(cb func(*object.Commit) error) error {
+	for {
+		c, err := it.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		
+		if err := cb(c); errors.Is(err, storer.ErrStop) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (it *firstParentIter) Close() {
+	it.next = nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeOpts(t *testing.T) {
+	tr := newTestRepo(t)
+	base := tr.commit("feat: base", map[string]string{"a.txt": "a"})
+	side := tr.commit("feat: side", map[string]string{"b.txt": "b"})
+	tr.reset(base)
+	main := tr.commit("fix: main", map[string]string{"c.txt": "c"})
+	tr.merge("Merge branch 'side'", main, side)
+	tr.commit("chore: after", nil)
+	
+	tests := []struct {
+		name   string
+		merges mergeOpts
+		want   []string
+	}{
+		{
+			name: "with defaults should include all commits",
+			want: []string{"feat: side", "feat: base", "fix: main", "Merge branch 'side'", "chore: after"},
+		},
+		{
+			name:   "with first parent should omit merged branch",
+			merges: mergeOpts{firstParent: true},
+			want:   []string{"feat: base", "fix: main", "Merge branch 'side'", "chore: after"},
+		},
+		{
+			name:   "with no merges should omit merge commits",
+			merges: mergeOpts{noMerges: true},
+			want:   []string{"feat: side", "feat: base", "fix: main", "chore: after"},
+		},
+		{
+			name:   "with merges only should only include merge commits",
+			merges: mergeOpts{mergesOnly: true},
+			want:   []string{"Merge branch 'side'"},
+		},
+		{
+			name:   "with first parent and no merges",
+			merges: mergeOpts{firstParent: true, noMerges: true},
+			want:   []string{"feat: base", "fix: main", "chore: after"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGitTemplVars(tr.repo, parseOpts{merges: tt.merges})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			messagesEq(t, got, tt.want...)
+		})
+	}
+	
+	t.Run("should expose merge and parents", func(t *testing.T) {
+		got, err := parseGitTemplVars(tr.repo, parseOpts{merges: mergeOpts{mergesOnly: true}})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		mustLen(t, got, 1)
+		if !got[0].IsMerge {
+			t.Error("expected merge commit")
+		}
+		mustLen(t, got[0].Parents, 2)
+		if got[0].Parents[0] != main.String() || got[0].Parents[1] != side.String() {
+			t.Errorf("unexpected parents: %v", got[0].Parents)
+		}
+	})
+	
+	t.Run("with first parent from revision should stop at excluded commit", func(t *testing.T) {
+		got, err := parseGitTemplVars(tr.repo, parseOpts{from: main.String(), merges: mergeOpts{firstParent: true}})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		messagesEq(t, got, "Merge branch 'side'", "chore: after")
+	})
+	
+	t.Run("with merge flag should override the merges of the config", func(t *testing.T) {
+		writeFile(t, filepath.Join(tr.dir, ".gitempl.yaml"), "no_merges: true\n")
+		defer os.Remove(filepath.Join(tr.dir, ".gitempl.yaml"))
+		
+		dir := t.TempDir()
+		tmpl, out := filepath.Join(dir, "merges.tmpl"), filepath.Join(dir, "out.txt")
+		writeFile(t, tmpl, `{{ range .Commits }}{{ .Subject }};{{ end }}`)
+		
+		cmd := newCmd()
+		cmd.SetArgs([]string{"--dir", tr.dir, "--template", tmpl, "--merges-only", out})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if want, got := "Merge branch 'side';", string(b); got != want {
+			t.Errorf("unexpected output:\n\twant: %q\n\tgot: %q", want, got)
+		}
+	})
+	
+	t.Run("lint with no merges should skip merge commits", func(t *testing.T) {
+		cmd := newCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"lint", "--dir", tr.dir})
+		if err := cmd.Execute(); err == nil {
+			t.Fatal("expected error for merge commit")
+		}
+		
+		cmd = newCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"lint", "--dir", tr.dir, "--no-merges"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+	})
+	
+	t.Run("lint with first parent should skip the merged branch", func(t *testing.T) {
+		issues, total, err := lintCommits(tr.repo, "", "HEAD", mergeOpts{firstParent: true, noMerges: true}, lintRules{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		mustLen(t, issues, 0)
+		if total != 3 {
+			t.Errorf("unexpected total: %d", total)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	
+	"github.com/spf13/cobra"
+)
+
+// module limits the commits and tags to a single module of a monorepo.
+type module struct {
+	// paths are the globs of the paths the commits must touch. When empty,
+	// all commits are included.
+	paths pathFilter
+	// tagPrefix is the prefix of the module's tags, i.e. pkg/foo/ for
+	// pkg/foo/v1.2.3. Tags without the prefix are ignored.
+	tagPrefix string
+}
+
+func (c *cli) registerModuleFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar((*[]string)(&c.mod.paths), "path", nil, "only include commits touching the path; may be repeated and may be a glob, i.e. pkg/foo or pkg/*/go.mod")
+	cmd.Flags().StringVar(&c.mod.tagPrefix, "tag-prefix", "", "only resolve releases from tags with the prefix, i.e. pkg/foo/ for pkg/foo/v1.2.3 tags")
+}
+
+// pathFilter is a set of globs matched against the paths of the files
+// changed by a commit.
+type pathFilter []string
+
+func (p pathFilter) validate() error {
+	for _, glob := range p {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid path glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// touches reports whether any of the files, or the old path of a renamed
+// file, matches any of the globs. An empty filter touches all files.
+func (p pathFilter) touches(files fileStatSlc) bool {
+	if len(p) == 0 {
+		return true
+	}
+	for _, f := range files {
+		for _, glob := range p {
+			if matchPath(glob, f.Path) || (f.OldPath != "" && matchPath(glob, f.OldPath)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPath reports whether the glob matches the file path or any of its
+// parent directories, so that a directory glob matches all the files in it.
+// The glob must be valid.
+func matchPath(glob, file string) bool {
+	glob = path.Clean(strings.TrimPrefix(glob, "./"))
+	for dir := file; dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+		if ok, _ := path.Match(glob, dir); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		name string
+		glob string
+		file string
+		want bool
+	}{
+		{name: "exact file", glob: "go.mod", file: "go.mod", want: true},
+		{name: "directory", glob: "pkg/foo", file: "pkg/foo/bar/baz.go", want: true},
+		{name: "directory with trailing slash", glob: "pkg/foo/", file: "pkg/foo/baz.go", want: true},
+		{name: "directory with leading dot", glob: "./pkg/foo", file: "pkg/foo/baz.go", want: true},
+		{name: "glob file", glob: "pkg/*/go.mod", file: "pkg/foo/go.mod", want: true},
+		{name: "glob directory", glob: "pkg/f*", file: "pkg/foo/baz.go", want: true},
+		{name: "sibling directory", glob: "pkg/foo", file: "pkg/foobar/baz.go", want: false},
+		{name: "glob does not cross directories", glob: "*.go", file: "pkg/foo/baz.go", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchPath(tt.glob, tt.file); got != tt.want {
+				t.Errorf("matchPath(%q, %q) = %t, want %t", tt.glob, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModule(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat(foo): first", map[string]string{"pkg/foo/a.go": "a"})
+	tr.tag("pkg/foo/v0.1.0", tr.commit("feat(bar): second", map[string]string{"pkg/bar/a.go": "a"}), "")
+	tr.tag("v1.0.0", tr.commit("fix(foo): third", map[string]string{"pkg/foo/a.go": "aa"}), "")
+	tr.commit("feat(bar): fourth", map[string]string{"pkg/bar/a.go": "aa"})
+	tr.commit("fix(foo): fifth", map[string]string{"pkg/foo/b.go": "b"})
+	
+	mod := module{paths: pathFilter{"pkg/foo"}, tagPrefix: "pkg/foo/"}
+	
+	t.Run("commits should only include commits touching paths", func(t *testing.T) {
+		commits, err := parseGitTemplVars(tr.repo, parseOpts{paths: mod.paths})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		messagesEq(t, commits, "feat(foo): first", "fix(foo): third", "fix(foo): fifth")
+		
+		releases, unreleased, err := parseReleases(tr.repo, commits, mod.tagPrefix)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		mustLen(t, releases, 1)
+		releaseEq(t, release{Tag: "pkg/foo/v0.1.0", Version: "0.1.0"}, releases[0], "feat(foo): first")
+		messagesEq(t, unreleased, "fix(foo): third", "fix(foo): fifth")
+	})
+	
+	t.Run("next version should use prefixed tags and commits touching paths", func(t *testing.T) {
+		got, err := nextVersion(tr.repo, "HEAD", mod, bumpRules{
+			minorTypes: []string{"feat"},
+			patchTypes: []string{"fix"},
+		}, nil, 1)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		want := versionBump{Previous: "pkg/foo/v0.1.0", Next: "pkg/foo/v0.1.1", Increment: bumpPatch}
+		if got != want {
+			t.Errorf("unexpected version:\n\twant: %+v\n\tgot: %+v", want, got)
+		}
+	})
+	
+	t.Run("next version should read the files of the commits through the cache", func(t *testing.T) {
+		cache := openCommitCache(tr.repo)
+		rules := bumpRules{minorTypes: []string{"feat"}, patchTypes: []string{"fix"}}
+		
+		want, err := nextVersion(tr.repo, "HEAD", mod, rules, cache, 2)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(cache.entries) != 3 {
+			t.Fatalf("unexpected cached commits:\n\twant: 3\n\tgot: %d", len(cache.entries))
+		}
+		for hash, e := range cache.entries {
+			if !e.HasFiles {
+				t.Errorf("expected files of cached commit %s", hash)
+			}
+		}
+		
+		got, err := nextVersion(tr.repo, "HEAD", mod, rules, cache, 2)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if got != want {
+			t.Errorf("unexpected version from cache:\n\twant: %+v\n\tgot: %+v", want, got)
+		}
+	})
+	
+	t.Run("TouchingPath should filter commits", func(t *testing.T) {
+		commits, err := parseGitTemplVars(tr.repo, parseOpts{stats: true})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		got, err := commitSlc(commits).TouchingPath("pkg/bar/*.go")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		messagesEq(t, got, "feat(bar): second", "feat(bar): fourth")
+	})
+	
+	t.Run("with invalid glob should error", func(t *testing.T) {
+		if _, err := parseGitTemplVars(tr.repo, parseOpts{paths: pathFilter{"pkg/["}}); err == nil {
+			t.Fatal("expected error for invalid glob")
+		}
+		if _, err := (commitSlc{}).TouchingPath("pkg/["); err == nil {
+			t.Fatal("expected error for invalid glob")
+		}
+	})
+}
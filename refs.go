@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	issueKindClosing     = "closing"
+	issueKindReferencing = "referencing"
+)
+
+// defaultPullRequestPattern matches the (#1234) suffix that squash merges
+// add to the subject.
+const defaultPullRequestPattern = `\((?:(?P<repo>[\w.-]+/[\w.-]+))?#(?P<id>\d+)\)\s*$`
+
+var (
+	// defaultIssuePatterns only match numbered issues, as any pattern for
+	// keys such as JIRA-123 also matches UTF-8 or SHA-256, so the keys of
+	// the projects are configured with the issues patterns.
+	defaultIssuePatterns = []string{
+		`(?:\b(?P<repo>[\w.-]+/[\w.-]+))?#(?P<id>\d+)\b`,
+	}
+	defaultClosingKeywords = []string{
+		"close", "closes", "closed",
+		"fix", "fixes", "fixed",
+		"resolve", "resolves", "resolved",
+	}
+)
+
+// issueRef is a reference to an issue or pull request, i.e. #12,
+// org/repo#7 or JIRA-123.
+type issueRef struct {
+	// Repo is the owner/repo of a reference to another repo, or empty for
+	// a reference to this repo.
+	Repo string
+	// ID is the number or key of the issue, i.e. 12 or JIRA-123.
+	ID string
+	// Kind is closing when the reference follows a closing keyword, such as
+	// Closes #12, or referencing otherwise.
+	Kind string
+}
+
+// String returns the reference as written, i.e. #12, org/repo#7 or JIRA-123.
+func (i issueRef) String() string {
+	if !isNumber(i.ID) {
+		return i.ID
+	}
+	return i.Repo + "#" + i.ID
+}
+
+// IsClosing is true for references that follow a closing keyword.
+func (i issueRef) IsClosing() bool {
+	return i.Kind == issueKindClosing
+}
+
+// referencesConfig are the patterns of the references extracted from the
+// commit messages. The patterns are regular expressions with an id named
+// group, and optionally a repo named group.
+type referencesConfig struct {
+	// PullRequest is matched against the subject of the commit.
+	PullRequest string `yaml:"pull_request" toml:"pull_request"`
+	// Issues are matched against the entire commit message.
+	Issues []string `yaml:"issues" toml:"issues"`
+	// ClosingKeywords preceding an issue make it a closing reference.
+	ClosingKeywords []string `yaml:"closing_keywords" toml:"closing_keywords"`
+}
+
+type refParser struct {
+	pullRequest *regexp.Regexp
+	issues      []*regexp.Regexp
+	closing     *regexp.Regexp
+}
+
+// newRefParser compiles the patterns of the config, with the defaults for
+// any that are empty.
+func newRefParser(cfg referencesConfig) (*refParser, error) {
+	if cfg.PullRequest == "" {
+		cfg.PullRequest = defaultPullRequestPattern
+	}
+	if len(cfg.Issues) == 0 {
+		cfg.Issues = defaultIssuePatterns
+	}
+	if len(cfg.ClosingKeywords) == 0 {
+		cfg.ClosingKeywords = defaultClosingKeywords
+	}
+	
+	var (
+		p   refParser
+		err error
+	)
+	p.pullRequest, err = compileRefPattern(cfg.PullRequest)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range cfg.Issues {
+		re, err := compileRefPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		p.issues = append(p.issues, re)
+	}
+	
+	keywords := make([]string, 0, len(cfg.ClosingKeywords))
+	for _, k := range cfg.ClosingKeywords {
+		keywords = append(keywords, regexp.QuoteMeta(k))
+	}
+	p.closing = regexp.MustCompile(`(?i)\b(?:` + strings.Join(keywords, "|") + `):?\s+$`)
+	
+	return &p, nil
+}
+
+func compileRefPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference pattern %q: %w", pattern, err)
+	}
+	if re.SubexpIndex("id") < 0 {
+		return nil, fmt.Errorf("invalid reference pattern %q: missing (?P<id>...) group", pattern)
+	}
+	return re, nil
+}
+
+// parse returns the pull request of the subject and the issues referenced by
+// the message. The pull request is not an issue.
+func (p *refParser) parse(msg string) (*issueRef, []issueRef) {
+	if p == nil {
+		return nil, nil
+	}
+	
+	var pr *issueRef
+	subject, _, _ := strings.Cut(msg, "\n")
+	if m := p.pullRequest.FindStringSubmatchIndex(subject); m != nil {
+		ref := newIssueRef(p.pullRequest, subject, m)
+		ref.Kind = ""
+		pr = &ref
+		msg = msg[:m[0]] + msg[m[1]:]
+	}
+	
+	var issues []issueRef
+	for _, re := range p.issues {
+		for _, m := range re.FindAllStringSubmatchIndex(msg, -1) {
+			ref := newIssueRef(re, msg, m)
+			if p.closing.MatchString(msg[:m[0]]) {
+				ref.Kind = issueKindClosing
+			}
+			
+			i := slices.IndexFunc(issues, func(i issueRef) bool {
+				return i.Repo == ref.Repo && i.ID == ref.ID
+			})
+			switch {
+			case i < 0:
+				issues = append(issues, ref)
+			case ref.IsClosing():
+				issues[i].Kind = issueKindClosing
+			}
+		}
+	}
+	
+	return pr, issues
+}
+
+func newIssueRef(re *regexp.Regexp, s string, m []int) issueRef {
+	ref := issueRef{Kind: issueKindReferencing}
+	if i := re.SubexpIndex("id"); m[2*i] >= 0 {
+		ref.ID = s[m[2*i]:m[2*i+1]]
+	}
+	if i := re.SubexpIndex("repo"); i >= 0 && m[2*i] >= 0 {
+		ref.Repo = s[m[2*i]:m[2*i+1]]
+	}
+	return ref
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ClosedIssues returns the issues closed by any of the commits, without
+// duplicates, in the order of the commits.
+func (c commitSlc) ClosedIssues() []issueRef {
+	var out []issueRef
+	for _, com := range c {
+		for _, issue := range com.Issues {
+			if issue.IsClosing() && !slices.Contains(out, issue) {
+				out = append(out, issue)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRefParser(t *testing.T) {
+	p, err := newRefParser(referencesConfig{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	
+	tests := []struct {
+		name       string
+		msg        string
+		wantPR     string
+		wantIssues []issueRef
+	}{
+		{
+			name:   "with squash merge suffix",
+			msg:    "feat: add thing (#1234)",
+			wantPR: "#1234",
+		},
+		{
+			name: "with closing references",
+			msg:  "fix: thing\n\nCloses #12\nFixes org/repo#7",
+			wantIssues: []issueRef{
+				{ID: "12", Kind: issueKindClosing},
+				{Repo: "org/repo", ID: "7", Kind: issueKindClosing},
+			},
+		},
+		{
+			name:   "with referencing references",
+			msg:    "fix: thing (#99)\n\nSee #12 and JIRA-123.\n\nRefs: PROJ-7",
+			wantPR: "#99",
+			wantIssues: []issueRef{
+				{ID: "12", Kind: issueKindReferencing},
+			},
+		},
+		{
+			name: "with dashed words should not be issues",
+			msg:  "fix: thing\n\nFixes UTF-8 handling for SHA-256 sums, see CVE-2024-1234",
+		},
+		{
+			name: "with duplicate references closing should win",
+			msg:  "fix: thing #12\n\nresolved: #12",
+			wantIssues: []issueRef{
+				{ID: "12", Kind: issueKindClosing},
+			},
+		},
+		{
+			name: "without references",
+			msg:  "chore: nothing to see here",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, issues := p.parse(tt.msg)
+			
+			var gotPR string
+			if pr != nil {
+				gotPR = pr.String()
+			}
+			if gotPR != tt.wantPR {
+				t.Errorf("unexpected pull request:\n\twant: %s\n\tgot: %s", tt.wantPR, gotPR)
+			}
+			if !slices.Equal(issues, tt.wantIssues) {
+				t.Errorf("unexpected issues:\n\twant: %v\n\tgot: %v", tt.wantIssues, issues)
+			}
+		})
+	}
+	
+	t.Run("with custom patterns", func(t *testing.T) {
+		p, err := newRefParser(referencesConfig{
+			PullRequest:     `Merge pull request !(?P<id>\d+)`,
+			Issues:          []string{`\bBUG-(?P<id>\d+)\b`},
+			ClosingKeywords: []string{"squashes"},
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		pr, issues := p.parse("Merge pull request !42 from branch\n\nSquashes BUG-1, see #2")
+		if pr == nil || pr.ID != "42" {
+			t.Errorf("unexpected pull request: %v", pr)
+		}
+		want := []issueRef{{ID: "1", Kind: issueKindClosing}}
+		if !slices.Equal(issues, want) {
+			t.Errorf("unexpected issues:\n\twant: %v\n\tgot: %v", want, issues)
+		}
+	})
+	
+	t.Run("with jira pattern", func(t *testing.T) {
+		p, err := newRefParser(referencesConfig{
+			Issues: []string{`#(?P<id>\d+)\b`, `\b(?P<id>(?:JIRA|PROJ)-\d+)\b`},
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		_, issues := p.parse("fix: thing\n\nSee #12 and JIRA-123 for UTF-8.\n\nRefs: PROJ-7")
+		want := []issueRef{
+			{ID: "12", Kind: issueKindReferencing},
+			{ID: "JIRA-123", Kind: issueKindReferencing},
+			{ID: "PROJ-7", Kind: issueKindReferencing},
+		}
+		if !slices.Equal(issues, want) {
+			t.Errorf("unexpected issues:\n\twant: %v\n\tgot: %v", want, issues)
+		}
+	})
+	
+	t.Run("with invalid patterns should error", func(t *testing.T) {
+		for _, cfg := range []referencesConfig{
+			{PullRequest: `(`},
+			{Issues: []string{`#\d+`}},
+		} {
+			if _, err := newRefParser(cfg); err == nil {
+				t.Errorf("expected error for config: %#v", cfg)
+			}
+		}
+	})
+}
+
+func TestCommitSlc_ClosedIssues(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first (#1)\n\nCloses #10\nSee #11", nil)
+	tr.commit("fix: second (#2)\n\nFixes #10, fixes org/repo#12", nil)
+	
+	p, err := newRefParser(referencesConfig{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	commits, err := parseGitTemplVars(tr.repo, parseOpts{refs: p})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	
+	mustLen(t, commits, 2)
+	if pr := commits[1].PullRequest; pr == nil || pr.String() != "#2" {
+		t.Errorf("unexpected pull request: %v", pr)
+	}
+	
+	var got []string
+	for _, issue := range commitSlc(commits).ClosedIssues() {
+		got = append(got, issue.String())
+	}
+	if want := "#10 org/repo#12"; strings.Join(got, " ") != want {
+		t.Errorf("unexpected closed issues:\n\twant: %s\n\tgot: %v", want, got)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/mod/semver"
+)
+
+type release struct {
+	Tag     string
+	Version string
+	Date    time.Time
+	Message string
+	Commits commitSlc
+}
+
+type tagRef struct {
+	name string
+	// version is the semantic version of the tag without its prefix, or
+	// an empty string when the tag is not a semantic version.
+	version string
+	message string
+	date    time.Time
+	commit  *object.Commit
+}
+
+// parseReleases associates each of the commits with the oldest tag that
+// contains it. The releases are returned newest first, with only the
+// releases that contain at least one of the provided commits. Commits
+// that are not contained by any tag are returned as unreleased. Only the
+// tags with the tag prefix are releases.
+func parseReleases(r *git.Repository, commits []commit, tagPrefix string) ([]release, commitSlc, error) {
+	tags, err := resolveTags(r, tagPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	var (
+		seen       = make(map[plumbing.Hash]bool)
+		commitTags = make(map[string]int)
+	)
+	for i, t := range tags {
+		iter := object.NewCommitPreorderIter(t.commit, seen, nil)
+		err := iter.ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			commitTags[c.Hash.String()] = i
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	
+	releases := make([]release, len(tags))
+	for i, t := range tags {
+		releases[i] = release{
+			Tag:     t.name,
+			Version: tagVersion(strings.TrimPrefix(t.name, tagPrefix)),
+			Date:    t.date,
+			Message: t.message,
+		}
+	}
+	
+	var unreleased commitSlc
+	for _, c := range commits {
+		i, ok := commitTags[c.Hash]
+		if !ok {
+			unreleased = append(unreleased, c)
+			continue
+		}
+		releases[i].Commits = append(releases[i].Commits, c)
+	}
+	
+	releases = slices.DeleteFunc(releases, func(rel release) bool {
+		return len(rel.Commits) == 0
+	})
+	slices.Reverse(releases)
+	
+	return releases, unreleased, nil
+}
+
+// resolveTags returns all lightweight and annotated tags with the prefix that
+// point at a commit, ordered oldest first by the date of the tagged commit.
+func resolveTags(r *git.Repository, prefix string) ([]tagRef, error) {
+	iter, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+	
+	var tags []tagRef
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		t := tagRef{
+			name:    name,
+			version: tagSemver(strings.TrimPrefix(name, prefix)),
+		}
+		
+		tagObj, err := r.TagObject(ref.Hash())
+		switch {
+		case err == nil:
+			c, err := tagObj.Commit()
+			if errors.Is(err, object.ErrUnsupportedObject) {
+				return nil // tags of trees and blobs are not releases
+			}
+			if err != nil {
+				return err
+			}
+			t.commit = c
+			t.message = strings.TrimSpace(tagObj.Message)
+			t.date = tagObj.Tagger.When
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			c, err := r.CommitObject(ref.Hash())
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			t.commit = c
+			t.date = c.Committer.When
+		default:
+			return err
+		}
+		
+		tags = append(tags, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	slices.SortFunc(tags, func(a, b tagRef) int {
+		if n := a.commit.Committer.When.Compare(b.commit.Committer.When); n != 0 {
+			return n
+		}
+		// prefer semver tags when multiple tags point at the same commit
+		if aV, bV := a.version != "", b.version != ""; aV != bV {
+			if aV {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.name, b.name)
+	})
+	
+	return tags, nil
+}
+
+// tagVersion returns the semantic version of the tag without the leading v,
+// or an empty string when the tag is not a semantic version.
+func tagVersion(tag string) string {
+	return strings.TrimPrefix(tagSemver(tag), "v")
+}
+
+func tagSemver(tag string) string {
+	v := tag
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReleases(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", map[string]string{"a.txt": "a"})
+	second := tr.commit("feat: second", map[string]string{"b.txt": "b"})
+	tr.tag("v0.1.0", second, "")
+	third := tr.commit("fix: third", map[string]string{"a.txt": "aa"})
+	tr.tag("v0.1.1", third, "release v0.1.1")
+	tr.tag("latest", third, "")
+	tr.commit("chore: fourth", map[string]string{"c.txt": "c"})
+	
+	t.Run("with entire history", func(t *testing.T) {
+		commits, err := parseGitTemplVars(tr.repo, parseOpts{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		releases, unreleased, err := parseReleases(tr.repo, commits, "")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		mustLen(t, releases, 2)
+		releaseEq(t, release{Tag: "v0.1.1", Version: "0.1.1", Message: "release v0.1.1"}, releases[0], "fix: third")
+		releaseEq(t, release{Tag: "v0.1.0", Version: "0.1.0"}, releases[1], "feat: first", "feat: second")
+		messagesEq(t, unreleased, "chore: fourth")
+		
+		if !releases[1].Date.Equal(tr.when.Add(-2 * time.Minute)) {
+			t.Errorf("unexpected lightweight tag date: %s", releases[1].Date)
+		}
+	})
+	
+	t.Run("with range should only include releases in range", func(t *testing.T) {
+		commits, err := parseGitTemplVars(tr.repo, parseOpts{from: "v0.1.0"})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		releases, unreleased, err := parseReleases(tr.repo, commits, "")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		mustLen(t, releases, 1)
+		releaseEq(t, release{Tag: "v0.1.1", Version: "0.1.1", Message: "release v0.1.1"}, releases[0], "fix: third")
+		messagesEq(t, unreleased, "chore: fourth")
+	})
+}
+
+func TestTagVersion(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "v1.2.3", want: "1.2.3"},
+		{tag: "1.2.3", want: "1.2.3"},
+		{tag: "v1.2.3-rc.1", want: "1.2.3-rc.1"},
+		{tag: "latest", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := tagVersion(tt.tag); got != tt.want {
+				t.Errorf("tagVersion(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func releaseEq(t *testing.T, want, got release, messages ...string) {
+	t.Helper()
+	
+	if want.Tag != got.Tag {
+		t.Errorf("Tags do not match:\n\twant: %s\n\tgot: %s", want.Tag, got.Tag)
+	}
+	if want.Version != got.Version {
+		t.Errorf("Versions do not match:\n\twant: %s\n\tgot: %s", want.Version, got.Version)
+	}
+	if want.Message != got.Message {
+		t.Errorf("Messages do not match:\n\twant: %s\n\tgot: %s", want.Message, got.Message)
+	}
+	messagesEq(t, got.Commits, messages...)
+}
+
+func messagesEq(t *testing.T, got commitSlc, want ...string) {
+	t.Helper()
+	
+	mustLen(t, got, len(want))
+	for i, want := range want {
+		if got := strings.TrimSpace(got[i].Message); got != want {
+			t.Errorf("unexpected message at %d:\n\twant: %s\n\tgot: %s", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	signatureTypeGPG  = "GPG"
+	signatureTypeSSH  = "SSH"
+	signatureTypeX509 = "X509"
+)
+
+// verification results of a signature, similar to the %G? of git log.
+const (
+	// signatureGood is a valid signature by a key of the keyring.
+	signatureGood = "good"
+	// signatureBad is a signature by a key of the keyring that does not
+	// match the commit.
+	signatureBad = "bad"
+	// signatureUnknownKey is a signature by a key missing from the keyring.
+	signatureUnknownKey = "unknown-key"
+	// signatureUnchecked is a signature that was not verified, because no
+	// keyring was provided or only GPG signatures can be verified.
+	signatureUnchecked = "unchecked"
+)
+
+// armor headers of the signature types.
+const (
+	armorGPG  = "-----BEGIN PGP SIGNATURE-----"
+	armorSSH  = "-----BEGIN SSH SIGNATURE-----"
+	armorX509 = "-----BEGIN SIGNED MESSAGE-----"
+)
+
+// sshSigMagic is the preamble of an SSH signature blob.
+const sshSigMagic = "SSHSIG"
+
+// signature is the signature of a commit.
+type signature struct {
+	// IsSigned is true when the commit has a signature.
+	IsSigned bool
+	// Type is one of GPG, SSH or X509.
+	Type string
+	// KeyID is the long key ID of a GPG signature, or the SHA256 fingerprint
+	// of the key of an SSH signature.
+	KeyID string
+	// Status is the result of the verification against the keyring, one of
+	// good, bad, unknown-key or unchecked. It is empty for unsigned commits.
+	Status string
+	// Signer is the identity of the key of a good signature.
+	Signer string
+}
+
+// IsVerified is true when the signature was verified by a key of the keyring.
+func (s signature) IsVerified() bool {
+	return s.Status == signatureGood
+}
+
+// newSignature returns the signature of the armored signature of a commit,
+// without verifying it. The key ID is empty when the signature fails to
+// parse.
+func newSignature(armored string) signature {
+	armored = strings.TrimSpace(armored)
+	if armored == "" {
+		return signature{}
+	}
+	
+	sig := signature{IsSigned: true}
+	switch {
+	case strings.HasPrefix(armored, armorGPG):
+		sig.Type = signatureTypeGPG
+		sig.KeyID = gpgKeyID(armored)
+	case strings.HasPrefix(armored, armorSSH):
+		sig.Type = signatureTypeSSH
+		sig.KeyID = sshKeyID(armored)
+	case strings.HasPrefix(armored, armorX509):
+		sig.Type = signatureTypeX509
+	}
+	return sig
+}
+
+func gpgKeyID(armored string) string {
+	block, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return ""
+	}
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return ""
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok || sig.IssuerKeyId == nil {
+		return ""
+	}
+	return fmt.Sprintf("%016X", *sig.IssuerKeyId)
+}
+
+func sshKeyID(armored string) string {
+	var b64 strings.Builder
+	for _, line := range strings.Split(armored, "\n") {
+		if line = strings.TrimSpace(line); !strings.HasPrefix(line, "-----") {
+			b64.WriteString(line)
+		}
+	}
+	blob, err := base64.StdEncoding.DecodeString(b64.String())
+	if err != nil {
+		return ""
+	}
+	blob, ok := bytes.CutPrefix(blob, []byte(sshSigMagic))
+	if !ok {
+		return ""
+	}
+	
+	var sig struct {
+		Version   uint32
+		PublicKey []byte
+		Rest      []byte `ssh:"rest"`
+	}
+	if err := ssh.Unmarshal(blob, &sig); err != nil {
+		return ""
+	}
+	key, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return ""
+	}
+	return ssh.FingerprintSHA256(key)
+}
+
+// readKeyring reads the armored GPG keyring once, so that every commit is
+// verified against the same parsed keys.
+func readKeyring(file string) (openpgp.EntityList, error) {
+	if file == "" {
+		return nil, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	
+	keyring, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyring %s: %w", file, err)
+	}
+	return keyring, nil
+}
+
+// verifySignature verifies the signature of the commit against the keyring.
+// The result depends on the keyring, so it is never cached.
+func (c *commit) verifySignature(obj *object.Commit, keyring openpgp.EntityList) {
+	if !c.Signature.IsSigned {
+		return
+	}
+	
+	c.Signature.Status, c.Signature.Signer = signatureUnchecked, ""
+	if len(keyring) == 0 || c.Signature.Type != signatureTypeGPG {
+		return
+	}
+	
+	// the keyring is parsed once by readKeyring, so the signature is checked
+	// the same as obj.Verify does, without parsing the keyring for every commit
+	encoded := &plumbing.MemoryObject{}
+	if err := obj.EncodeWithoutSignature(encoded); err != nil {
+		c.Signature.Status = signatureBad
+		return
+	}
+	signed, err := encoded.Reader()
+	if err != nil {
+		c.Signature.Status = signatureBad
+		return
+	}
+	
+	entity, err := openpgp.CheckArmoredDetachedSignature(keyring, signed, strings.NewReader(obj.PGPSignature), nil)
+	switch {
+	case err == nil:
+		c.Signature.Status = signatureGood
+		if id := entity.PrimaryIdentity(); id != nil {
+			c.Signature.Signer = id.Name
+		}
+	case errors.Is(err, pgperrors.ErrUnknownIssuer):
+		c.Signature.Status = signatureUnknownKey
+	default:
+		c.Signature.Status = signatureBad
+	}
+}
+
+// Unsigned returns the commits without a signature.
+func (c commitSlc) Unsigned() commitSlc {
+	return c.filter(func(c commit) bool {
+		return !c.Signature.IsSigned
+	})
+}
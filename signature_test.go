@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNewSignature(t *testing.T) {
+	key := newTestKey(t, "Jane Doe", "jane@example.com")
+	var gpgSig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&gpgSig, key, strings.NewReader("data"), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	sshSig := "-----BEGIN SSH SIGNATURE-----\n" +
+		base64.StdEncoding.EncodeToString(append([]byte(sshSigMagic), ssh.Marshal(struct {
+			Version       uint32
+			PublicKey     []byte
+			Namespace     string
+			Reserved      string
+			HashAlgorithm string
+			Signature     []byte
+		}{Version: 1, PublicKey: sshPub.Marshal(), Namespace: "git", HashAlgorithm: "sha512"})...)) +
+		"\n-----END SSH SIGNATURE-----\n"
+	
+	tests := []struct {
+		name string
+		sig  string
+		want signature
+	}{
+		{
+			name: "unsigned",
+		},
+		{
+			name: "gpg",
+			sig:  gpgSig.String(),
+			want: signature{IsSigned: true, Type: signatureTypeGPG, KeyID: fmt.Sprintf("%016X", key.PrimaryKey.KeyId)},
+		},
+		{
+			name: "ssh",
+			sig:  sshSig,
+			want: signature{IsSigned: true, Type: signatureTypeSSH, KeyID: ssh.FingerprintSHA256(sshPub)},
+		},
+		{
+			name: "x509",
+			sig:  "-----BEGIN SIGNED MESSAGE-----\nMIAG\n-----END SIGNED MESSAGE-----\n",
+			want: signature{IsSigned: true, Type: signatureTypeX509},
+		},
+		{
+			name: "invalid gpg",
+			sig:  "-----BEGIN PGP SIGNATURE-----\n\nnope\n-----END PGP SIGNATURE-----\n",
+			want: signature{IsSigned: true, Type: signatureTypeGPG},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newSignature(tt.sig); got != tt.want {
+				t.Errorf("signatures do not match:\n\twant: %#v\n\tgot: %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCmdSignature(t *testing.T) {
+	var (
+		jane = newTestKey(t, "Jane Doe", "jane@example.com")
+		john = newTestKey(t, "John Smith", "john@example.com")
+	)
+	
+	tr := newTestRepo(t)
+	tr.commit("feat: unsigned", nil)
+	tr.signedCommit("feat: signed by jane", jane)
+	tr.signedCommit("feat: signed by john", john)
+	
+	keyring := filepath.Join(t.TempDir(), "keyring.asc")
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := jane.Serialize(w); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err.Error())
+	}
+	writeFile(t, keyring, buf.String())
+	
+	tmpl := `{{ range .Commits }}{{ .Subject }}|{{ .Signature.IsSigned }}|{{ .Signature.Type }}|{{ .Signature.Status }}|{{ .Signature.Signer }}
+{{ end }}{{ range .Commits.Unsigned }}unsigned: {{ .Subject }}{{ end }}`
+	
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "without keyring",
+			want: "feat: unsigned|false|||\n" +
+				"feat: signed by jane|true|GPG|unchecked|\n" +
+				"feat: signed by john|true|GPG|unchecked|\n" +
+				"unsigned: feat: unsigned",
+		},
+		{
+			name: "with keyring",
+			args: []string{"--keyring", keyring},
+			want: "feat: unsigned|false|||\n" +
+				"feat: signed by jane|true|GPG|good|Jane Doe <jane@example.com>\n" +
+				"feat: signed by john|true|GPG|unknown-key|\n" +
+				"unsigned: feat: unsigned",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate(t, tr.dir, tmpl, tt.args...)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got != tt.want {
+				t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", tt.want, got)
+			}
+		})
+	}
+	
+	t.Run("with invalid keyring should error", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.asc")
+		writeFile(t, invalid, "not a keyring")
+		
+		if _, err := renderTemplate(t, tr.dir, tmpl, "--keyring", invalid); err == nil {
+			t.Fatal("expected error for invalid keyring")
+		}
+	})
+}
+
+func newTestKey(t *testing.T, name, email string) *openpgp.Entity {
+	t.Helper()
+	
+	key, err := openpgp.NewEntity(name, "", email, &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return key
+}
+
+// signedCommit commits the worktree signed by the key.
+func (tr *testRepo) signedCommit(msg string, key *openpgp.Entity) plumbing.Hash {
+	tr.t.Helper()
+	
+	wt, err := tr.repo.Worktree()
+	if err != nil {
+		tr.t.Fatal(err.Error())
+	}
+	
+	tr.when = tr.when.Add(time.Minute)
+	h, err := wt.Commit(msg, &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            tr.signature(),
+		SignKey:           key,
+	})
+	if err != nil {
+		tr.t.Fatal(err.Error())
+	}
+	return h
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+const (
+	fileStatusAdded    = "added"
+	fileStatusModified = "modified"
+	fileStatusDeleted  = "deleted"
+	fileStatusRenamed  = "renamed"
+)
+
+// maxGraphWidth matches the width of the +/- graph used by git diff --stat.
+const maxGraphWidth = 53
+
+type fileStat struct {
+	// Commit is the hash of the commit that changed the file.
+	Commit    string
+	Path      string
+	OldPath   string
+	Additions int
+	Deletions int
+	// Status is one of added, modified, deleted or renamed.
+	Status string
+	// Binary is true for binary files, which have no line counts.
+	Binary bool
+}
+
+// Name returns the path of the file, or "old => new" for renamed files.
+func (f fileStat) Name() string {
+	if f.Status == fileStatusRenamed {
+		return fmt.Sprintf("%s => %s", f.OldPath, f.Path)
+	}
+	return f.Path
+}
+
+type fileStatSlc []fileStat
+
+// String returns the stats in the git diff --stat format.
+func (f fileStatSlc) String() string {
+	stats := make(object.FileStats, 0, len(f))
+	for _, fs := range f {
+		if fs.Binary {
+			continue
+		}
+		stats = append(stats, object.FileStat{
+			Name:     fs.Name(),
+			Addition: fs.Additions,
+			Deletion: fs.Deletions,
+		})
+	}
+	return stats.String()
+}
+
+func (f fileStatSlc) totals() (additions, deletions int) {
+	for _, fs := range f {
+		additions += fs.Additions
+		deletions += fs.Deletions
+	}
+	return additions, deletions
+}
+
+// commitFiles diffs the commit against its first parent, with renames
+// detected, and returns the stats of each changed file.
+func commitFiles(c *object.Commit) (fileStatSlc, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	
+	parentTree := &object.Tree{}
+	if c.NumParents() != 0 {
+		parent, err := c.Parents().Next()
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	
+	patch, err := parentTree.Patch(tree)
+	if err != nil {
+		return nil, err
+	}
+	
+	var files fileStatSlc
+	for _, fp := range patch.FilePatches() {
+		fs := fileStat{Commit: c.Hash.String()}
+		from, to := fp.Files()
+		switch {
+		case from == nil && to == nil:
+			continue
+		case from == nil:
+			fs.Path, fs.Status = to.Path(), fileStatusAdded
+		case to == nil:
+			fs.Path, fs.Status = from.Path(), fileStatusDeleted
+		case from.Path() != to.Path():
+			fs.Path, fs.OldPath, fs.Status = to.Path(), from.Path(), fileStatusRenamed
+		default:
+			fs.Path, fs.Status = to.Path(), fileStatusModified
+		}
+		fs.Binary = fp.IsBinary()
+		
+		for _, chunk := range fp.Chunks() {
+			content := chunk.Content()
+			if content == "" {
+				continue
+			}
+			lines := strings.Count(content, "\n")
+			if !strings.HasSuffix(content, "\n") {
+				lines++
+			}
+			switch chunk.Type() {
+			case fdiff.Add:
+				fs.Additions += lines
+			case fdiff.Delete:
+				fs.Deletions += lines
+			}
+		}
+		
+		files = append(files, fs)
+	}
+	
+	return files, nil
+}
+
+// commitsFiles computes the files of each of the commits with a pool of jobs
+// workers, returning the files in the same order as the commits.
+func commitsFiles(r *git.Repository, commits []*object.Commit, jobs int) ([]fileStatSlc, error) {
+	// the object cache of a storage is not safe for concurrent use, so each
+	// worker reads the objects through its own storage
+	fsStorage, ok := r.Storer.(*filesystem.Storage)
+	if !ok {
+		jobs = 1
+	}
+	
+	var (
+		files = make([]fileStatSlc, len(commits))
+		errs  = make([]error, len(commits))
+		next  = make(chan int)
+		wg    sync.WaitGroup
+	)
+	for range min(max(jobs, 1), len(commits)) {
+		var s storer.EncodedObjectStorer = r.Storer
+		if fsStorage != nil {
+			s = filesystem.NewStorage(fsStorage.Filesystem(), cache.NewObjectLRUDefault())
+		}
+		
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				c, err := object.GetCommit(s, commits[i].Hash)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				files[i], errs[i] = commitFiles(c)
+			}
+		}()
+	}
+	for i := range commits {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	
+	return files, errors.Join(errs...)
+}
+
+// statsFields are the fields and funcs that require the files of the
+// commits to be computed.
+var statsFields = []string{
+	"Files", "Stats", "TotalAdditions", "TotalDeletions",
+	"TouchingPath", "statsHTMLTable", "json",
+}
+
+// templateNeedsStats reports whether any of the templates references the
+// file stats of the commits, so that the diffs are only computed when used.
+func templateNeedsStats(t *template.Template) bool {
+	return templateReferences(t, statsFields...)
+}
+
+// templateReferences reports whether any of the templates references any of
+// the fields or funcs with the names.
+func templateReferences(t *template.Template, names ...string) bool {
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil && nodeReferences(tmpl.Tree.Root, names) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeReferences(node parse.Node, names []string) bool {
+	var idents []string
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if nodeReferences(child, names) {
+				return true
+			}
+		}
+		return false
+	case *parse.ActionNode:
+		return nodeReferences(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if nodeReferences(cmd, names) {
+				return true
+			}
+		}
+		return false
+	case *parse.CommandNode:
+		if len(n.Args) > 1 && filterReferences(n.Args[0], n.Args[1], names) {
+			return true
+		}
+		for _, arg := range n.Args {
+			if nodeReferences(arg, names) {
+				return true
+			}
+		}
+		return false
+	case *parse.IfNode:
+		return nodeReferences(n.Pipe, names) || nodeReferences(n.List, names) || nodeReferences(n.ElseList, names)
+	case *parse.RangeNode:
+		return nodeReferences(n.Pipe, names) || nodeReferences(n.List, names) || nodeReferences(n.ElseList, names)
+	case *parse.WithNode:
+		return nodeReferences(n.Pipe, names) || nodeReferences(n.List, names) || nodeReferences(n.ElseList, names)
+	case *parse.TemplateNode:
+		return nodeReferences(n.Pipe, names)
+	case *parse.ChainNode:
+		if nodeReferences(n.Node, names) {
+			return true
+		}
+		idents = n.Field
+	case *parse.FieldNode:
+		idents = n.Ident
+	case *parse.VariableNode:
+		idents = n.Ident
+	case *parse.IdentifierNode:
+		idents = []string{n.Ident}
+	}
+	
+	for _, ident := range idents {
+		if slices.Contains(names, ident) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldFilters are the methods of the commits whose first argument is the
+// path of a field of the commits.
+var fieldFilters = []string{"KeepByField", "DropByField", "KeepByFieldMatch", "DropByFieldMatch"}
+
+// filterReferences reports whether the string argument of a call to a filter
+// of the commits references any of the names by the path of a field, such as
+// Files in .Commits.KeepByField "Files.Path" "a.txt", or by the fields of a
+// Where expression, such as TotalAdditions in .Commits.Where "TotalAdditions > 0".
+func filterReferences(fn, arg parse.Node, names []string) bool {
+	s, ok := arg.(*parse.StringNode)
+	if !ok {
+		return false
+	}
+	
+	var idents []string
+	switch fn := fn.(type) {
+	case *parse.FieldNode:
+		idents = fn.Ident
+	case *parse.ChainNode:
+		idents = fn.Field
+	case *parse.VariableNode:
+		idents = fn.Ident
+	}
+	if len(idents) == 0 {
+		return false
+	}
+	
+	var fields []string
+	switch method := idents[len(idents)-1]; {
+	case slices.Contains(fieldFilters, method):
+		fields = []string{s.Text}
+	case method == "Where":
+		tokens, err := lexWhere(s.Text)
+		if err != nil {
+			return false
+		}
+		for _, tok := range tokens {
+			if tok.kind == whereIdent {
+				fields = append(fields, tok.value)
+			}
+		}
+	}
+	
+	for _, field := range fields {
+		path, err := newFieldPath(field)
+		if err != nil {
+			continue
+		}
+		if slices.ContainsFunc(path, func(name string) bool { return slices.Contains(names, name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// statsIndex maps the Stats of the commits to their files, so that
+// statsHTMLTable can render the .Stats string of a commit. The string can not
+// tell apart commits with the same stats, so their files are mapped without a
+// commit and link to the path instead of the blob of either commit.
+type statsIndex map[string]fileStatSlc
+
+func (idx statsIndex) add(commits []commit) {
+	for _, com := range commits {
+		if com.Stats == "" {
+			continue
+		}
+		files, ok := idx[com.Stats]
+		if !ok {
+			idx[com.Stats] = com.Files
+			continue
+		}
+		if len(files) > 0 && files[0].Commit != "" && files[0].Commit != com.Hash {
+			shared := slices.Clone(files)
+			for i := range shared {
+				shared[i].Commit = ""
+			}
+			idx[com.Stats] = shared
+		}
+	}
+}
+
+// statsFuncs returns the template funcs that render the file stats, with
+// links to the forge.
+func statsFuncs(fg *forge, idx statsIndex) template.FuncMap {
+	return template.FuncMap{
+		// statsHTMLTable accepts a commit, its .Files, or its .Stats
+		"statsHTMLTable": func(v any) (string, error) {
+			switch v := v.(type) {
+			case commit:
+				return statsHTMLTable(fg, v.Files), nil
+			case fileStatSlc:
+				return statsHTMLTable(fg, v), nil
+			case string:
+				if v == "" {
+					return "", nil
+				}
+				files, ok := idx[v]
+				if !ok {
+					return "", errors.New("statsHTMLTable: the stats are not the .Stats of a commit")
+				}
+				return statsHTMLTable(fg, files), nil
+			default:
+				return "", fmt.Errorf("statsHTMLTable: unsupported value of type %T; must be a commit, or its .Files or .Stats", v)
+			}
+		},
+	}
+}
+
+// statsHTMLTable renders the files as a markdown table, with links to the
+// files on the forge, or relative links without a forge.
+func statsHTMLTable(fg *forge, files fileStatSlc) string {
+	if len(files) == 0 {
+		return ""
+	}
+	
+	type row struct {
+		file, count, diff string
+	}
+	var (
+		rows = make([]row, 0, len(files))
+		
+		fileLenMax, countLenMax, diffLenMax = len(" File "), len(" Count "), len(" Diff ")
+	)
+	for _, f := range files {
+		adds, dels := graphWidths(f.Additions, f.Deletions)
+		
+		var diff string
+		if adds > 0 {
+			diff += newColorSpan("green", strings.Repeat("+", adds))
+		}
+		if dels > 0 {
+			diff += newColorSpan("red", strings.Repeat("-", dels))
+		}
+		
+		r := row{
+			file:  newFileLink(fg, f),
+			count: newCount(strconv.Itoa(f.Additions + f.Deletions)),
+			diff:  " " + diff,
+		}
+		fileLenMax = max(fileLenMax, len(r.file)+1)
+		countLenMax = max(countLenMax, len(r.count)+1)
+		diffLenMax = max(diffLenMax, len(r.diff)+1)
+		rows = append(rows, r)
+	}
+	
+	pad := func(s string, n int) string {
+		return s + strings.Repeat(" ", max(0, n-len(s)))
+	}
+	
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(
+		"|%s|%s|%s|\n",
+		pad(" File", fileLenMax),
+		pad(" Count", countLenMax),
+		pad(" Diff", diffLenMax),
+	))
+	sb.WriteString(fmt.Sprintf(
+		"|%s|%s|%s|\n",
+		strings.Repeat("-", fileLenMax),
+		strings.Repeat("-", countLenMax),
+		strings.Repeat("-", diffLenMax),
+	))
+	for _, r := range rows {
+		sb.WriteString(fmt.Sprintf(
+			"|%s|%s|%s|\n",
+			pad(r.file, fileLenMax),
+			pad(r.count, countLenMax),
+			pad(r.diff, diffLenMax),
+		))
+	}
+	return sb.String()
+}
+
+// graphWidths scales the additions and deletions to fit in the +/- graph
+// the same way git diff --stat does.
+func graphWidths(additions, deletions int) (int, int) {
+	total := additions + deletions
+	if total <= maxGraphWidth {
+		return additions, deletions
+	}
+	
+	scale := func(n int) int {
+		if n == 0 {
+			return 0
+		}
+		return 1 + (n * (maxGraphWidth - 1) / total)
+	}
+	return scale(additions), scale(deletions)
+}
+
+func newCount(s string) string {
+	return fmt.Sprintf(" **%s**", s)
+}
+
+func newColorSpan(color, s string) string {
+	return fmt.Sprintf(`<span style="color:%s">%s</span>`, color, s)
+}
+
+func newFileLink(fg *forge, f fileStat) string {
+	// a deleted file only exists in the parent, so link to the commit
+	dest := fg.fileURL(f.Commit, f.Path)
+	if f.Status == fileStatusDeleted {
+		dest = fg.commitURL(f.Commit)
+	}
+	if dest == "" || f.Commit == "" {
+		dest = (&url.URL{Path: f.Path}).String()
+	}
+	return fmt.Sprintf(" [%s](%s)", f.Name(), dest)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestCommitFiles(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", map[string]string{
+		"main.go":          "package main\n\nfunc main() {}\n",
+		"docs/read me.md":  "# docs\n",
+		"docs/ünïcödé.txt": "hello\nworld\n",
+	})
+	
+	wt, err := tr.repo.Worktree()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := wt.Move("main.go", "cmd/main.go"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := wt.Remove("docs/read me.md"); err != nil {
+		t.Fatal(err.Error())
+	}
+	tr.commit("refactor: second", map[string]string{
+		"docs/ünïcödé.txt": "hello\nthere\nworld\n",
+	})
+	
+	commits, err := parseGitTemplVars(tr.repo, parseOpts{stats: true, jobs: 2})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	mustLen(t, commits, 2)
+	
+	t.Run("initial commit should add all files", func(t *testing.T) {
+		got := commits[0]
+		filesEq(t, fileStatSlc{
+			{Commit: got.Hash, Path: "docs/read me.md", Additions: 1, Status: fileStatusAdded},
+			{Commit: got.Hash, Path: "docs/ünïcödé.txt", Additions: 2, Status: fileStatusAdded},
+			{Commit: got.Hash, Path: "main.go", Additions: 3, Status: fileStatusAdded},
+		}, got.Files)
+		if got.TotalAdditions != 6 || got.TotalDeletions != 0 {
+			t.Errorf("unexpected totals: +%d -%d", got.TotalAdditions, got.TotalDeletions)
+		}
+	})
+	
+	t.Run("second commit should have renamed, deleted and modified files", func(t *testing.T) {
+		got := commits[1]
+		filesEq(t, fileStatSlc{
+			{Commit: got.Hash, Path: "docs/read me.md", Deletions: 1, Status: fileStatusDeleted},
+			{Commit: got.Hash, Path: "docs/ünïcödé.txt", Additions: 1, Status: fileStatusModified},
+			{Commit: got.Hash, Path: "cmd/main.go", OldPath: "main.go", Status: fileStatusRenamed},
+		}, got.Files)
+		if got.TotalAdditions != 1 || got.TotalDeletions != 1 {
+			t.Errorf("unexpected totals: +%d -%d", got.TotalAdditions, got.TotalDeletions)
+		}
+		if !strings.Contains(got.Stats, "main.go => cmd/main.go") {
+			t.Errorf("expected rename in stats:\n%s", got.Stats)
+		}
+	})
+}
+
+func Test_statsHTML(t *testing.T) {
+	files := fileStatSlc{
+		{Path: "wild-workouts/.gitignore", Additions: 36, Status: fileStatusAdded},
+		{Path: "wild-workouts/LICENSE", Additions: 16, Deletions: 5, Status: fileStatusModified},
+		{Path: "docs/read me.md", OldPath: "README.md", Additions: 1, Deletions: 1, Status: fileStatusRenamed},
+		{Path: "wild-workouts/internal/common/client/trainings/openapi_client_gen.go", Additions: 1004, Status: fileStatusAdded},
+		{Path: "wild-workouts/sql/schema.sql", Deletions: 6, Status: fileStatusDeleted},
+	}
+	
+	want := `| File                                                                                                                                         | Count    | Diff                                                                                   |
+|----------------------------------------------------------------------------------------------------------------------------------------------|----------|----------------------------------------------------------------------------------------|
+| [wild-workouts/.gitignore](wild-workouts/.gitignore)                                                                                         | **36**   | <span style="color:green">++++++++++++++++++++++++++++++++++++</span>                  |
+| [wild-workouts/LICENSE](wild-workouts/LICENSE)                                                                                               | **21**   | <span style="color:green">++++++++++++++++</span><span style="color:red">-----</span>  |
+| [README.md => docs/read me.md](docs/read%20me.md)                                                                                            | **2**    | <span style="color:green">+</span><span style="color:red">-</span>                     |
+| [wild-workouts/internal/common/client/trainings/openapi_client_gen.go](wild-workouts/internal/common/client/trainings/openapi_client_gen.go) | **1004** | <span style="color:green">+++++++++++++++++++++++++++++++++++++++++++++++++++++</span> |
+| [wild-workouts/sql/schema.sql](wild-workouts/sql/schema.sql)                                                                                 | **6**    | <span style="color:red">------</span>                                                  |
+`
+	got := statsHTMLTable(nil, files)
+	if got != want {
+		t.Errorf("unexpected table:\n\twant:\n%s\n\tgot:\n%s", want, got)
+	}
+}
+
+func filesEq(t *testing.T, want, got fileStatSlc) {
+	t.Helper()
+	
+	mustLen(t, got, len(want))
+	for i, want := range want {
+		if got := got[i]; want != got {
+			t.Errorf("files do not match:\n\twant: %#v\n\tgot: %#v", want, got)
+		}
+	}
+}
+
+func TestTemplateNeedsStats(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want bool
+	}{
+		{name: "without stats", tmpl: `{{ range .Commits }}{{ .CC.Desc }}{{ end }}`, want: false},
+		{name: "with stats field", tmpl: `{{ range .Commits }}{{ .Stats }}{{ end }}`, want: true},
+		{name: "with files in condition", tmpl: `{{ range .Commits }}{{ if .Files }}x{{ end }}{{ end }}`, want: true},
+		{name: "with totals in chain", tmpl: `{{ (index .Commits 0).TotalAdditions }}`, want: true},
+		{name: "with stats func", tmpl: `{{ range .Commits }}{{ statsHTMLTable .Files }}{{ end }}`, want: true},
+		{name: "with TouchingPath", tmpl: `{{ with .Commits.TouchingPath "pkg" }}{{ len . }}{{ end }}`, want: true},
+		{name: "with json", tmpl: `{{ json . }}`, want: true},
+		{name: "with files field of filter", tmpl: `{{ range .Commits.KeepByField "Files.Path" "a.txt" }}{{ .Subject }}{{ end }}`, want: true},
+		{name: "with totals field of match filter", tmpl: `{{ $c := .Commits }}{{ len ($c.DropByFieldMatch "TotalDeletions" "0") }}`, want: true},
+		{name: "with totals in where", tmpl: `{{ range .Commits.Where "Type == \"feat\" && TotalAdditions > 0" }}{{ end }}`, want: true},
+		{name: "without stats in where", tmpl: `{{ range .Commits.Where "Desc == \"Files\"" }}{{ end }}`, want: false},
+		{name: "with other field of filter", tmpl: `{{ range .Commits.KeepByField "Desc" "Files" }}{{ end }}`, want: false},
+		{name: "with stats in partial", tmpl: `{{ define "row" }}{{ .Stats }}{{ end }}{{ range .Commits }}{{ template "row" . }}{{ end }}`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("t").Funcs(funcMap).Funcs((*forge)(nil).funcs()).Funcs(statsFuncs(nil, nil)).Parse(tt.tmpl)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got := templateNeedsStats(tmpl); got != tt.want {
+				t.Errorf("templateNeedsStats(%q) = %t, want %t", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCmdStatsHTMLTable(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat: first", map[string]string{"a.txt": "a\n"})
+	tr.commit("feat: second", map[string]string{"a.txt": "a\nb\n", "b.txt": "b\n"})
+	
+	want, err := renderTemplate(t, tr.dir, `{{ range .Commits }}{{ .Files | statsHTMLTable }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(want, "[b.txt](b.txt)") {
+		t.Fatalf("unexpected table:\n%s", want)
+	}
+	
+	got, err := renderTemplate(t, tr.dir, `{{ range .Commits }}{{ .Stats | statsHTMLTable }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if got != want {
+		t.Errorf("tables of .Stats and .Files do not match:\n\twant: %s\n\tgot: %s", want, got)
+	}
+	
+	if _, err := renderTemplate(t, tr.dir, `{{ statsHTMLTable "rando | 1 +" }}`); err == nil {
+		t.Error("expected error for stats of no commit")
+	}
+	if _, err := renderTemplate(t, tr.dir, `{{ statsHTMLTable 1 }}`); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	
+	t.Run("with same stats should not link other commit", func(t *testing.T) {
+		tr := newTestRepo(t)
+		tr.commit("feat: first", map[string]string{"a.txt": "a\n"})
+		second := tr.commit("fix: second", map[string]string{"a.txt": "b\n"})
+		third := tr.commit("fix: third", map[string]string{"a.txt": "c\n"})
+		tr.remote("https://github.com/org/repo.git")
+		
+		got, err := renderTemplate(t, tr.dir, `{{ range .Commits }}{{ .Hash }}
+{{ .Stats | statsHTMLTable }}{{ statsHTMLTable . }}{{ end }}`)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		
+		_, thirdTables, _ := strings.Cut(got, third.String()+"\n")
+		if strings.Contains(thirdTables, "blob/"+second.String()) {
+			t.Errorf("unexpected link to other commit:\n%s", thirdTables)
+		}
+		for _, want := range []string{
+			"[a.txt](a.txt)",
+			"[a.txt](https://github.com/org/repo/blob/" + third.String() + "/a.txt)",
+		} {
+			if !strings.Contains(thirdTables, want) {
+				t.Errorf("missing expected link:\n\twant: %s\n\tgot:\n%s", want, thirdTables)
+			}
+		}
+	})
+}
+
+func TestTemplateReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want bool
+	}{
+		{name: "without reference", tmpl: `{{ range .Commits }}{{ .CC.Desc }}{{ end }}`, want: false},
+		{name: "with field", tmpl: `{{ .NextVersion.Next }}`, want: true},
+		{name: "with json", tmpl: `{{ json . }}`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("t").Funcs(funcMap).Parse(tt.tmpl)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got := templateReferences(tmpl, "NextVersion", "json"); got != tt.want {
+				t.Errorf("templateReferences(%q) = %t, want %t", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
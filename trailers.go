@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// trailer keys of the identities credited by a commit, compared case
+// insensitively as git does.
+const (
+	trailerCoAuthoredBy = "co-authored-by"
+	trailerSignedOffBy  = "signed-off-by"
+	trailerReviewedBy   = "reviewed-by"
+	trailerAckedBy      = "acked-by"
+)
+
+// trailers are the identities of the trailers of a commit message.
+type trailers struct {
+	CoAuthors   []identity
+	SignedOffBy []identity
+	ReviewedBy  []identity
+	AckedBy     []identity
+}
+
+// parseTrailers parses the identity trailers of the last paragraph of the
+// message. The last paragraph is only a trailer block when every line of it
+// is a "Key: value" trailer, a conventional commit footer, or the continuation
+// of one.
+func parseTrailers(msg string) trailers {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return trailers{}
+	}
+	
+	var lines []string
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		switch {
+		case len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			lines[len(lines)-1] += " " + strings.TrimSpace(line)
+		case isTrailer(line):
+			lines = append(lines, line)
+		default:
+			return trailers{}
+		}
+	}
+	
+	var out trailers
+	for _, line := range lines {
+		key, value, _ := strings.Cut(line, ":")
+		var dst *[]identity
+		switch strings.ToLower(key) {
+		case trailerCoAuthoredBy:
+			dst = &out.CoAuthors
+		case trailerSignedOffBy:
+			dst = &out.SignedOffBy
+		case trailerReviewedBy:
+			dst = &out.ReviewedBy
+		case trailerAckedBy:
+			dst = &out.AckedBy
+		default:
+			continue
+		}
+		if id, ok := parseIdentity(value); ok {
+			*dst = append(*dst, id)
+		}
+	}
+	return out
+}
+
+// isTrailer reports whether the line is a "Key: value" trailer, where the key
+// has no whitespace, or a footer of a conventional commit, such as
+// "Closes #12" or "BREAKING CHANGE: y".
+func isTrailer(line string) bool {
+	if footerLineRegex.MatchString(line) {
+		return true
+	}
+	key, _, ok := strings.Cut(line, ":")
+	return ok && key != "" && !strings.ContainsAny(key, " \t")
+}
+
+// parseIdentity parses the "Name <email>" of a trailer.
+func parseIdentity(v string) (identity, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return identity{}, false
+	}
+	if addr, err := mail.ParseAddress(v); err == nil {
+		return identity{Name: addr.Name, Email: addr.Address}, true
+	}
+	
+	name, email, ok := strings.Cut(v, "<")
+	if !ok {
+		return identity{Name: v}, true
+	}
+	email, _, _ = strings.Cut(email, ">")
+	return identity{Name: strings.TrimSpace(name), Email: strings.TrimSpace(email)}, true
+}
+
+// contributor is a person credited by the commits, as the author or a
+// co-author.
+type contributor struct {
+	Name  string
+	Email string
+	// Commits is the number of commits authored or co-authored.
+	Commits int
+}
+
+// String returns the contributor in the git format of "Name <email>".
+func (c contributor) String() string {
+	return identity{Name: c.Name, Email: c.Email}.String()
+}
+
+// Contributors returns the authors and co-authors of the commits, without
+// duplicates, in the order they first appear. Contributors are the same
+// person when their emails are equal ignoring case, or their names when they
+// have no email.
+func (c commitSlc) Contributors() []contributor {
+	var (
+		out   []contributor
+		index = make(map[string]int)
+	)
+	for _, com := range c {
+		seen := make(map[string]bool)
+		for _, id := range append([]identity{com.Author}, com.CoAuthors...) {
+			key := strings.ToLower(id.Email)
+			if key == "" {
+				key = id.Name
+			}
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			
+			i, ok := index[key]
+			if !ok {
+				i = len(out)
+				index[key] = i
+				out = append(out, contributor{Name: id.Name, Email: id.Email})
+			}
+			out[i].Commits++
+		}
+	}
+	return out
+}
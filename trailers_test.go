@@ -0,0 +1,147 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTrailers(t *testing.T) {
+	var (
+		jane = identity{Name: "Jane Doe", Email: "jane@example.com"}
+		john = identity{Name: "John Smith", Email: "john@example.com"}
+	)
+	
+	tests := []struct {
+		name string
+		msg  string
+		want trailers
+	}{
+		{
+			name: "with every trailer",
+			msg: "feat: thing\n\nbody\n\n" +
+				"Co-authored-by: Jane Doe <jane@example.com>\n" +
+				"Signed-off-by: John Smith <john@example.com>\n" +
+				"Reviewed-by: Jane Doe <jane@example.com>\n" +
+				"Acked-by: John Smith <john@example.com>\n",
+			want: trailers{
+				CoAuthors:   []identity{jane},
+				SignedOffBy: []identity{john},
+				ReviewedBy:  []identity{jane},
+				AckedBy:     []identity{john},
+			},
+		},
+		{
+			name: "with keys of any case and other trailers",
+			msg:  "fix: thing\n\nco-authored-by: Jane Doe <jane@example.com>\nRefs: #12\nCO-AUTHORED-BY: John Smith <john@example.com>",
+			want: trailers{CoAuthors: []identity{jane, john}},
+		},
+		{
+			name: "with continuation line",
+			msg:  "fix: thing\n\nCo-authored-by: Jane Doe\n <jane@example.com>",
+			want: trailers{CoAuthors: []identity{jane}},
+		},
+		{
+			name: "with name that is not a valid address",
+			msg:  "fix: thing\n\nCo-authored-by: J. Doe <jane@example.com>",
+			want: trailers{CoAuthors: []identity{{Name: "J. Doe", Email: "jane@example.com"}}},
+		},
+		{
+			name: "with breaking change footers",
+			msg: "feat!: thing\n\nbody\n\n" +
+				"BREAKING CHANGE: y\n" +
+				"Co-authored-by: Jane Doe <jane@example.com>\n" +
+				"BREAKING-CHANGE: z\n" +
+				"Signed-off-by: John Smith <john@example.com>",
+			want: trailers{
+				CoAuthors:   []identity{jane},
+				SignedOffBy: []identity{john},
+			},
+		},
+		{
+			name: "with issue footers",
+			msg:  "feat: x\n\nCloses #12\nCo-authored-by: Jane Doe <jane@example.com>\nRefs #13\nSigned-off-by: John Smith <john@example.com>",
+			want: trailers{
+				CoAuthors:   []identity{jane},
+				SignedOffBy: []identity{john},
+			},
+		},
+		{
+			name: "with trailers outside the last paragraph",
+			msg:  "fix: thing\n\nCo-authored-by: Jane Doe <jane@example.com>\n\nthe end",
+		},
+		{
+			name: "with prose in the last paragraph",
+			msg:  "fix: thing\n\nCo-authored-by: Jane Doe <jane@example.com>\nand some prose",
+		},
+		{
+			name: "with only a subject",
+			msg:  "Co-authored-by: Jane Doe <jane@example.com>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTrailers(tt.msg)
+			
+			for _, f := range []struct {
+				name      string
+				want, got []identity
+			}{
+				{name: "CoAuthors", want: tt.want.CoAuthors, got: got.CoAuthors},
+				{name: "SignedOffBy", want: tt.want.SignedOffBy, got: got.SignedOffBy},
+				{name: "ReviewedBy", want: tt.want.ReviewedBy, got: got.ReviewedBy},
+				{name: "AckedBy", want: tt.want.AckedBy, got: got.AckedBy},
+			} {
+				if !slices.Equal(f.want, f.got) {
+					t.Errorf("%s do not match:\n\twant: %v\n\tgot: %v", f.name, f.want, f.got)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitSlc_Contributors(t *testing.T) {
+	commits := commitSlc{
+		{
+			Author:    identity{Name: "Jane Doe", Email: "jane@example.com"},
+			CoAuthors: []identity{{Name: "John Smith", Email: "john@example.com"}},
+		},
+		{
+			Author: identity{Name: "John", Email: "JOHN@example.com"},
+			CoAuthors: []identity{
+				{Name: "John Smith", Email: "john@example.com"},
+				{Name: "Bot"},
+			},
+		},
+		{
+			Author: identity{Name: "Jane D.", Email: "jane@example.com"},
+		},
+	}
+	
+	want := []contributor{
+		{Name: "Jane Doe", Email: "jane@example.com", Commits: 2},
+		{Name: "John Smith", Email: "john@example.com", Commits: 2},
+		{Name: "Bot", Commits: 1},
+	}
+	if got := commits.Contributors(); !slices.Equal(want, got) {
+		t.Errorf("contributors do not match:\n\twant: %v\n\tgot: %v", want, got)
+	}
+}
+
+func TestCmdContributors(t *testing.T) {
+	tr := newTestRepo(t)
+	h := tr.commit("feat: paired\n\nCo-authored-by: John Smith <john@example.com>\nSigned-off-by: Jane Doe <jane@example.com>", nil)
+	tr.commit("fix: solo", nil)
+	
+	got, err := renderTemplate(t, tr.dir, `{{ range .Contributors }}{{ . }} {{ .Commits }}
+{{ end }}{{ range .Commits }}{{ .HashShort }}{{ range .CoAuthors }} {{ .Name }}{{ end }}{{ range .SignedOffBy }} {{ .Email }}{{ end }}
+{{ end }}`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	
+	want := "Jane Doe <jane@example.com> 2\nJohn Smith <john@example.com> 1\n" + h.String()[:7] + " John Smith jane@example.com\n"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", want, got)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	bumpNone  = ""
+	bumpPatch = "patch"
+	bumpMinor = "minor"
+	bumpMajor = "major"
+)
+
+var bumpOrder = []string{bumpNone, bumpPatch, bumpMinor, bumpMajor}
+
+type bumpRules struct {
+	// minorTypes are the conventional commit types that bump the minor version.
+	minorTypes []string
+	// patchTypes are the conventional commit types that bump the patch version.
+	patchTypes []string
+	// minorPreMajor bumps the minor version instead of the major version for
+	// breaking changes while the major version is 0.
+	minorPreMajor bool
+}
+
+// versionBump is the next semantic version calculated from the commits since
+// the latest semver tag.
+type versionBump struct {
+	// Previous is the latest semver tag, or v0.0.0 when there is none.
+	Previous string
+	// Next is the next version. When none of the commits since Previous
+	// warrant a release, Next is equal to Previous.
+	Next string
+	// Increment is one of major, minor, patch, or empty for no release.
+	Increment string
+}
+
+func (v versionBump) String() string {
+	return v.Next
+}
+
+func (c *cli) newVersionCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "version",
+		Short: "semantic version utilities driven by conventional commits",
+	}
+	cmd.AddCommand(c.newVersionNextCmd())
+	return &cmd
+}
+
+func (c *cli) newVersionNextCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:          "next",
+		Short:        "print the next semantic version from the commits since the latest semver tag",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := git.PlainOpen(c.dir)
+			if err != nil {
+				return err
+			}
+			
+			cache := openCommitCache(r)
+			v, err := nextVersion(r, c.to, c.mod, c.bump, cache, runtime.NumCPU())
+			if err != nil {
+				return err
+			}
+			if err := cache.save(); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "failed to write commit cache: %s\n", err)
+			}
+			
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), v.Next)
+			return err
+		},
+		Example: `  # print the next version of the current HEAD
+> gitempl version next
+
+# print the next version where perf commits bump the minor version
+> gitempl version next --bump-minor-types feat,perf
+
+# print the next version of the pkg/foo module, tagged as pkg/foo/v1.2.3
+> gitempl version next --path pkg/foo --tag-prefix pkg/foo/`,
+	}
+	
+	cmd.Flags().StringVar(&c.to, "to", "HEAD", "revision to calculate the next version for")
+	c.registerModuleFlags(&cmd)
+	
+	return &cmd
+}
+
+func (c *cli) registerBumpFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&c.bump.minorTypes, "bump-minor-types", []string{"feat"}, "conventional commit types that bump the minor version")
+	cmd.PersistentFlags().StringSliceVar(&c.bump.patchTypes, "bump-patch-types", []string{"fix"}, "conventional commit types that bump the patch version")
+	cmd.PersistentFlags().BoolVar(&c.bump.minorPreMajor, "bump-minor-pre-major", false, "breaking changes bump the minor version while the major version is 0")
+}
+
+// nextVersion calculates the next version of the to revision from the latest
+// semver tag reachable from it and the conventional commits since that tag.
+// For a module, only its prefixed tags and the commits touching its paths
+// are considered, and the versions keep the tag prefix. The commits are read
+// through the cache, and their files diffed by jobs workers.
+func nextVersion(r *git.Repository, to string, mod module, rules bumpRules, cache *commitCache, jobs int) (versionBump, error) {
+	latest, err := latestSemverTag(r, to, mod.tagPrefix)
+	if err != nil {
+		return versionBump{}, err
+	}
+	
+	prev, from := strings.TrimPrefix(latest, mod.tagPrefix), latest
+	if prev == "" {
+		prev = "v0.0.0"
+	}
+	
+	commits, err := parseGitTemplVars(r, parseOpts{
+		from:  from,
+		to:    to,
+		paths: mod.paths,
+		jobs:  jobs,
+		cache: cache,
+	})
+	if err != nil {
+		return versionBump{}, err
+	}
+	
+	inc := bumpNone
+	major := semver.Major(tagSemver(prev))
+	for _, com := range commits {
+		if !com.IsConventional {
+			continue
+		}
+		if i := rules.increment(major, com.CC); slices.Index(bumpOrder, i) > slices.Index(bumpOrder, inc) {
+			inc = i
+		}
+	}
+	
+	next, err := incrementVersion(prev, inc)
+	if err != nil {
+		return versionBump{}, err
+	}
+	
+	return versionBump{
+		Previous:  mod.tagPrefix + prev,
+		Next:      mod.tagPrefix + next,
+		Increment: inc,
+	}, nil
+}
+
+func (b bumpRules) increment(major string, cc conventional) string {
+	switch {
+	case cc.IsBreaking && b.minorPreMajor && major == "v0":
+		return bumpMinor
+	case cc.IsBreaking:
+		return bumpMajor
+	case slices.Contains(b.minorTypes, cc.Type):
+		return bumpMinor
+	case slices.Contains(b.patchTypes, cc.Type):
+		return bumpPatch
+	default:
+		return bumpNone
+	}
+}
+
+// latestSemverTag returns the name of the highest semver tag with the prefix
+// reachable from the to revision, or an empty string when there is none.
+func latestSemverTag(r *git.Repository, to, prefix string) (string, error) {
+	tags, err := resolveTags(r, prefix)
+	if err != nil {
+		return "", err
+	}
+	
+	tagsByCommit := make(map[string][]tagRef)
+	for _, t := range tags {
+		if t.version == "" {
+			continue
+		}
+		h := t.commit.Hash.String()
+		tagsByCommit[h] = append(tagsByCommit[h], t)
+	}
+	if len(tagsByCommit) == 0 {
+		return "", nil
+	}
+	
+	iter, err := logRange(r, "", to, false)
+	if err != nil {
+		return "", err
+	}
+	
+	var latest tagRef
+	err = iter.ForEach(func(c *object.Commit) error {
+		for _, t := range tagsByCommit[c.Hash.String()] {
+			if latest.name == "" || semver.Compare(t.version, latest.version) > 0 {
+				latest = t
+			}
+		}
+		return nil
+	})
+	return latest.name, err
+}
+
+// incrementVersion applies the increment to the version, keeping the tag's
+// format (with or without the v prefix). A pre-release version is released
+// as is when it already covers the increment, i.e. v1.1.0-rc.1 with a minor
+// increment becomes v1.1.0.
+func incrementVersion(version, inc string) (string, error) {
+	if inc == bumpNone {
+		return version, nil
+	}
+	
+	sv := tagSemver(version)
+	if sv == "" {
+		return "", fmt.Errorf("invalid semantic version %q", version)
+	}
+	
+	core := strings.TrimPrefix(semver.Canonical(sv), "v")
+	pre := semver.Prerelease(sv)
+	core = strings.TrimSuffix(core, pre)
+	
+	parts := strings.SplitN(core, ".", 3)
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "", fmt.Errorf("invalid semantic version %q: %w", version, err)
+		}
+		nums[i] = n
+	}
+	major, minor, patch := nums[0], nums[1], nums[2]
+	
+	if pre != "" {
+		covered := bumpMajor
+		switch {
+		case patch != 0:
+			covered = bumpPatch
+		case minor != 0:
+			covered = bumpMinor
+		}
+		if slices.Index(bumpOrder, inc) <= slices.Index(bumpOrder, covered) {
+			inc = bumpNone
+		}
+	}
+	
+	switch inc {
+	case bumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case bumpMinor:
+		minor, patch = minor+1, 0
+	case bumpPatch:
+		patch++
+	}
+	
+	var prefix string
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch), nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNextVersion(t *testing.T) {
+	defaultRules := bumpRules{
+		minorTypes: []string{"feat"},
+		patchTypes: []string{"fix"},
+	}
+	
+	tests := []struct {
+		name     string
+		tag      string
+		messages []string
+		rules    bumpRules
+		want     versionBump
+	}{
+		{
+			name:     "without tags should bump from v0.0.0",
+			messages: []string{"feat: first"},
+			rules:    defaultRules,
+			want:     versionBump{Previous: "v0.0.0", Next: "v0.1.0", Increment: bumpMinor},
+		},
+		{
+			name:     "with fix should bump patch",
+			tag:      "v1.2.3",
+			messages: []string{"fix: patch", "chore: deps"},
+			rules:    defaultRules,
+			want:     versionBump{Previous: "v1.2.3", Next: "v1.2.4", Increment: bumpPatch},
+		},
+		{
+			name:     "with feat and fix should bump minor",
+			tag:      "v1.2.3",
+			messages: []string{"fix: patch", "feat: new"},
+			rules:    defaultRules,
+			want:     versionBump{Previous: "v1.2.3", Next: "v1.3.0", Increment: bumpMinor},
+		},
+		{
+			name:     "with breaking marker should bump major",
+			tag:      "v1.2.3",
+			messages: []string{"feat(api)!: new"},
+			rules:    defaultRules,
+			want:     versionBump{Previous: "v1.2.3", Next: "v2.0.0", Increment: bumpMajor},
+		},
+		{
+			name:     "with breaking footer should bump major",
+			tag:      "v1.2.3",
+			messages: []string{"fix: new\n\nBREAKING-CHANGE: removed the thing"},
+			rules:    defaultRules,
+			want:     versionBump{Previous: "v1.2.3", Next: "v2.0.0", Increment: bumpMajor},
+		},
+		{
+			name:     "with breaking change pre 1.0 and minor pre major should bump minor",
+			tag:      "v0.2.3",
+			messages: []string{"feat!: new"},
+			rules:    bumpRules{minorTypes: []string{"feat"}, minorPreMajor: true},
+			want:     versionBump{Previous: "v0.2.3", Next: "v0.3.0", Increment: bumpMinor},
+		},
+		{
+			name:     "with perf as minor type should bump minor",
+			tag:      "v1.2.3",
+			messages: []string{"perf: faster"},
+			rules:    bumpRules{minorTypes: []string{"feat", "perf"}},
+			want:     versionBump{Previous: "v1.2.3", Next: "v1.3.0", Increment: bumpMinor},
+		},
+		{
+			name:     "without releasable commits should keep version",
+			tag:      "1.2.3",
+			messages: []string{"chore: deps", "not conventional"},
+			rules:    defaultRules,
+			want:     versionBump{Previous: "1.2.3", Next: "1.2.3", Increment: bumpNone},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestRepo(t)
+			h := tr.commit("feat: initial", map[string]string{"a.txt": "a"})
+			if tt.tag != "" {
+				tr.tag(tt.tag, h, "")
+			}
+			for _, msg := range tt.messages {
+				tr.commit(msg, nil)
+			}
+			
+			got, err := nextVersion(tr.repo, "HEAD", module{}, tt.rules, nil, 1)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if tt.want != got {
+				t.Errorf("unexpected version bump:\n\twant: %#v\n\tgot: %#v", tt.want, got)
+			}
+		})
+	}
+	
+	t.Run("should use highest reachable semver tag", func(t *testing.T) {
+		tr := newTestRepo(t)
+		tr.tag("v1.10.0", tr.commit("feat: initial", nil), "")
+		tr.tag("v1.9.1", tr.commit("fix: backport", nil), "")
+		tr.commit("fix: another", nil)
+		
+		got, err := nextVersion(tr.repo, "HEAD", module{}, defaultRules, nil, 1)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if want := "v1.10.1"; got.Next != want {
+			t.Errorf("unexpected next version:\n\twant: %s\n\tgot: %s", want, got.Next)
+		}
+	})
+	
+	t.Run("version next cmd should print next version", func(t *testing.T) {
+		tr := newTestRepo(t)
+		tr.tag("v1.0.0", tr.commit("feat: initial", nil), "")
+		tr.commit("perf: faster", nil)
+		
+		cmd := newCmd()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"version", "next", "--dir", tr.dir, "--bump-patch-types", "fix,perf"})
+		
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err.Error())
+		}
+		if got, want := strings.TrimSpace(buf.String()), "v1.0.1"; got != want {
+			t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", want, got)
+		}
+	})
+}
+
+func TestIncrementVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		inc     string
+		want    string
+	}{
+		{version: "v1.2.3", inc: bumpPatch, want: "v1.2.4"},
+		{version: "v1.2.3", inc: bumpMinor, want: "v1.3.0"},
+		{version: "v1.2.3", inc: bumpMajor, want: "v2.0.0"},
+		{version: "1.2.3", inc: bumpMajor, want: "2.0.0"},
+		{version: "v1.2.3+build.1", inc: bumpPatch, want: "v1.2.4"},
+		{version: "v1.3.0-rc.1", inc: bumpMinor, want: "v1.3.0"},
+		{version: "v1.3.0-rc.1", inc: bumpPatch, want: "v1.3.0"},
+		{version: "v1.3.0-rc.1", inc: bumpMajor, want: "v2.0.0"},
+		{version: "v2.0.0-beta", inc: bumpMajor, want: "v2.0.0"},
+		{version: "v1.2.3", inc: bumpNone, want: "v1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.inc, func(t *testing.T) {
+			got, err := incrementVersion(tt.version, tt.inc)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got != tt.want {
+				t.Errorf("incrementVersion(%q, %q) = %q, want %q", tt.version, tt.inc, got, tt.want)
+			}
+		})
+	}
+}
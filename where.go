@@ -0,0 +1,425 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode"
+)
+
+// whereCache caches the parsed expressions, so that templates filtering
+// within a range only parse each expression once.
+var whereCache sync.Map
+
+// Where returns the commits matching the boolean expression, such as:
+//
+//	Type in ["feat", "fix"] && Scope != "deps" && !Author.IsBot
+//
+// The operands are the fields of KeepByField compared with string, number or
+// boolean literals:
+//
+//	FIELD == VALUE, FIELD != VALUE   the field matches the value, or not
+//	FIELD in [VALUE, ...]            the field matches any of the values
+//	FIELD =~ PATTERN, FIELD !~ PATTERN
+//	                                 the field matches the pattern of
+//	                                 KeepByFieldMatch, or not
+//	FIELD < N, <=, >, >=             the numeric field compares to the number
+//	FIELD                            the field is true, non-zero or non-empty
+//
+// combined with !, && and || and grouped with parentheses. Strings are
+// quoted with double or single quotes.
+func (c commitSlc) Where(expr string) (commitSlc, error) {
+	x, err := parseWhere(expr)
+	if err != nil {
+		return nil, err
+	}
+	return c.filter(x.eval), nil
+}
+
+type whereExpr interface {
+	eval(c commit) bool
+}
+
+type (
+	whereOr  struct{ l, r whereExpr }
+	whereAnd struct{ l, r whereExpr }
+	whereNot struct{ x whereExpr }
+	
+	// whereTruth is a field used as a boolean.
+	whereTruth struct{ path fieldPath }
+	
+	// whereIn is the ==, != and in comparisons of a field with values.
+	whereIn struct {
+		path   fieldPath
+		values []string
+		not    bool
+	}
+	
+	whereMatch struct {
+		path fieldPath
+		re   *regexp.Regexp
+		not  bool
+	}
+	
+	whereCompare struct {
+		path fieldPath
+		op   string
+		n    float64
+	}
+)
+
+func (x whereOr) eval(c commit) bool  { return x.l.eval(c) || x.r.eval(c) }
+func (x whereAnd) eval(c commit) bool { return x.l.eval(c) && x.r.eval(c) }
+func (x whereNot) eval(c commit) bool { return !x.x.eval(c) }
+
+func (x whereTruth) eval(c commit) bool {
+	return x.path.matchesValue(reflect.ValueOf(c), func(v reflect.Value) bool {
+		truth, _ := template.IsTrue(v.Interface())
+		return truth
+	})
+}
+
+func (x whereIn) eval(c commit) bool {
+	for _, v := range x.values {
+		if x.path.matches(c, v) {
+			return !x.not
+		}
+	}
+	return x.not
+}
+
+func (x whereMatch) eval(c commit) bool {
+	return x.path.matchesPattern(c, x.re) != x.not
+}
+
+func (x whereCompare) eval(c commit) bool {
+	return x.path.matchesValue(reflect.ValueOf(c), func(v reflect.Value) bool {
+		var n float64
+		switch {
+		case v.CanInt():
+			n = float64(v.Int())
+		case v.CanUint():
+			n = float64(v.Uint())
+		case v.CanFloat():
+			n = v.Float()
+		}
+		switch x.op {
+		case "<":
+			return n < x.n
+		case "<=":
+			return n <= x.n
+		case ">":
+			return n > x.n
+		default:
+			return n >= x.n
+		}
+	})
+}
+
+// whereToken is a token of an expression. Literal strings have their quotes
+// removed.
+type whereToken struct {
+	kind  whereTokenKind
+	value string
+	pos   int
+}
+
+type whereTokenKind int
+
+const (
+	whereEOF whereTokenKind = iota
+	whereIdent
+	whereString
+	whereNumber
+	whereOp
+)
+
+// whereOps are the operators, the longest first.
+var whereOps = []string{"&&", "||", "==", "!=", "=~", "!~", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","}
+
+func lexWhere(expr string) ([]whereToken, error) {
+	var tokens []whereToken
+	for i := 0; i < len(expr); {
+		r := rune(expr[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(expr) && expr[end] != expr[i] {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			s := expr[i : end+1]
+			if r == '\'' {
+				s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			v, err := strconv.Unquote(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, whereToken{kind: whereString, value: v, pos: i})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(expr) && unicode.IsDigit(rune(expr[i+1]))):
+			end := i + 1
+			for end < len(expr) && (unicode.IsDigit(rune(expr[end])) || expr[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, whereToken{kind: whereNumber, value: expr[i:end], pos: i})
+			i = end
+		case r == '_' || unicode.IsLetter(r):
+			end := i + 1
+			for end < len(expr) && (expr[end] == '_' || expr[end] == '.' || unicode.IsLetter(rune(expr[end])) || unicode.IsDigit(rune(expr[end]))) {
+				end++
+			}
+			tokens = append(tokens, whereToken{kind: whereIdent, value: expr[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, o := range whereOps {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", r, i)
+			}
+			tokens = append(tokens, whereToken{kind: whereOp, value: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, whereToken{kind: whereEOF, pos: len(expr)}), nil
+}
+
+// whereParser is a recursive descent parser of the grammar:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" or ")" | field [ op operand ]
+//	operand = literal | "[" [ literal { "," literal } ] "]"
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+// parseWhere parses the expression, or returns the cached expression when it
+// was parsed before.
+func parseWhere(expr string) (whereExpr, error) {
+	if x, ok := whereCache.Load(expr); ok {
+		return x.(whereExpr), nil
+	}
+	
+	tokens, err := lexWhere(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid where expression %q: %w", expr, err)
+	}
+	p := whereParser{tokens: tokens}
+	x, err := p.parseOr()
+	if err == nil && p.peek().kind != whereEOF {
+		err = p.errorf("unexpected %q", p.peek().value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid where expression %q: %w", expr, err)
+	}
+	
+	whereCache.Store(expr, x)
+	return x, nil
+}
+
+func (p *whereParser) peek() whereToken {
+	return p.tokens[p.pos]
+}
+
+func (p *whereParser) next() whereToken {
+	t := p.tokens[p.pos]
+	if t.kind != whereEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token when it is the operator.
+func (p *whereParser) accept(op string) bool {
+	if t := p.peek(); t.kind == whereOp && t.value == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) errorf(format string, args ...any) error {
+	return fmt.Errorf(format+" at offset %d", append(args, p.peek().pos)...)
+}
+
+func (p *whereParser) parseOr() (whereExpr, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = whereOr{l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *whereParser) parseAnd() (whereExpr, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = whereAnd{l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *whereParser) parseUnary() (whereExpr, error) {
+	if p.accept("!") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return whereNot{x: x}, nil
+	}
+	if p.accept("(") {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected )")
+		}
+		return x, nil
+	}
+	
+	t := p.peek()
+	if t.kind != whereIdent {
+		return nil, p.errorf("expected field")
+	}
+	p.next()
+	path, err := newFieldPath(t.value)
+	if err != nil {
+		return nil, fmt.Errorf("%w at offset %d", err, t.pos)
+	}
+	
+	op := p.peek()
+	switch {
+	case op.kind == whereIdent && op.value == "in":
+		p.next()
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return whereIn{path: path, values: values}, nil
+	case op.kind != whereOp:
+		return whereTruth{path: path}, nil
+	}
+	
+	switch op.value {
+	case "==", "!=":
+		p.next()
+		v, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return whereIn{path: path, values: []string{v}, not: op.value == "!="}, nil
+	case "=~", "!~":
+		p.next()
+		v, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		re, err := compilePattern(v)
+		if err != nil {
+			return nil, err
+		}
+		return whereMatch{path: path, re: re, not: op.value == "!~"}, nil
+	case "<", "<=", ">", ">=":
+		p.next()
+		if leaf, _ := path.leafType(commitType); !isNumeric(leaf) {
+			return nil, fmt.Errorf("field %q is not a number at offset %d", t.value, t.pos)
+		}
+		num := p.peek()
+		if num.kind != whereNumber {
+			return nil, p.errorf("expected number")
+		}
+		p.next()
+		n, err := strconv.ParseFloat(num.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", num.value, num.pos)
+		}
+		return whereCompare{path: path, op: op.value, n: n}, nil
+	default:
+		return whereTruth{path: path}, nil
+	}
+}
+
+// parseList parses a list of literals, or a single literal.
+func (p *whereParser) parseList() ([]string, error) {
+	if !p.accept("[") {
+		v, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return []string{v}, nil
+	}
+	
+	var values []string
+	for !p.accept("]") {
+		if len(values) > 0 && !p.accept(",") {
+			return nil, p.errorf("expected , or ]")
+		}
+		v, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// parseLiteral parses a string, number or boolean literal as the string the
+// fields are compared with.
+func (p *whereParser) parseLiteral() (string, error) {
+	t := p.peek()
+	switch {
+	case t.kind == whereString, t.kind == whereNumber:
+	case t.kind == whereIdent && (t.value == "true" || t.value == "false"):
+	default:
+		return "", p.errorf("expected string, number or boolean")
+	}
+	p.next()
+	return t.value, nil
+}
+
+func isNumeric(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommitSlc_Where(t *testing.T) {
+	commits := commitSlc{
+		{
+			Author:         identity{Name: "Jane Doe", Email: "jane@example.com"},
+			IsConventional: true,
+			Message:        "feat(api): add endpoint",
+			CC:             conventional{Type: "feat", Scope: "api", Desc: "add endpoint"},
+			TotalAdditions: 120,
+		},
+		{
+			Author:         identity{Name: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com"},
+			IsConventional: true,
+			Message:        "fix(deps): bump parser",
+			CC:             conventional{Type: "fix", Scope: "deps", Desc: "bump parser"},
+			TotalAdditions: 2,
+		},
+		{
+			Author:         identity{Name: "John Smith", Email: "john@example.com"},
+			IsConventional: true,
+			Issues:         []issueRef{{ID: "12", Kind: issueKindClosing}},
+			Message:        "fix(web): escape input",
+			CC:             conventional{Type: "fix", Scope: "web", Desc: "escape input", IsBreaking: true},
+			TotalAdditions: 30,
+		},
+		{
+			Author:  identity{Name: "renovate-bot", Email: "bot@example.com"},
+			Message: "Update dependencies",
+		},
+	}
+	
+	tests := []struct {
+		name    string
+		expr    string
+		wantIdx []int
+	}{
+		{
+			name:    "with in, not equal and negated method",
+			expr:    `Type in ["feat","fix"] && Scope != "deps" && !Author.IsBot`,
+			wantIdx: []int{0, 2},
+		},
+		{
+			name:    "with or and parentheses",
+			expr:    `(Type == 'feat' || CC.IsBreaking) && TotalAdditions >= 30`,
+			wantIdx: []int{0, 2},
+		},
+		{
+			name:    "with bool literal",
+			expr:    `IsConventional == false`,
+			wantIdx: []int{3},
+		},
+		{
+			name:    "with truthy list field",
+			expr:    `Issues`,
+			wantIdx: []int{2},
+		},
+		{
+			name:    "with nested list field",
+			expr:    `Issues.ID == 12`,
+			wantIdx: []int{2},
+		},
+		{
+			name:    "with pattern match",
+			expr:    `Subject =~ "ire:^(FEAT|update)" && Author !~ "*[[]bot]"`,
+			wantIdx: []int{0, 3},
+		},
+		{
+			name:    "with number comparison",
+			expr:    `TotalAdditions < 30`,
+			wantIdx: []int{1, 3},
+		},
+		{
+			name:    "with and before or",
+			expr:    `Type == "feat" || Type == "fix" && Scope == "web"`,
+			wantIdx: []int{0, 2},
+		},
+		{
+			name:    "with identity",
+			expr:    `Author in ["jane@example.com", "John Smith"]`,
+			wantIdx: []int{0, 2},
+		},
+		{
+			name:    "without match",
+			expr:    `Type == "perf"`,
+			wantIdx: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := commits.Where(tt.expr)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			mustLen(t, got, len(tt.wantIdx))
+			for i, idx := range tt.wantIdx {
+				commitEq(t, commits[idx], got[i])
+			}
+		})
+	}
+	
+	errTests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "unknown field", expr: `Rando == "x"`, wantErr: `unknown commit field "Rando"`},
+		{name: "missing operand", expr: `Type ==`, wantErr: "expected string, number or boolean at offset 7"},
+		{name: "unterminated string", expr: `Type == "feat`, wantErr: "unterminated string at offset 8"},
+		{name: "unclosed parenthesis", expr: `(Type == "feat"`, wantErr: "expected ) at offset 15"},
+		{name: "unclosed list", expr: `Type in ["feat" "fix"]`, wantErr: "expected , or ] at offset 16"},
+		{name: "trailing tokens", expr: `Type == "feat" "fix"`, wantErr: `unexpected "fix" at offset 15`},
+		{name: "non numeric comparison", expr: `Type > 1`, wantErr: `field "Type" is not a number`},
+		{name: "invalid pattern", expr: `Type =~ "re:("`, wantErr: "invalid pattern"},
+		{name: "unexpected character", expr: `Type == "feat" & Scope`, wantErr: `unexpected '&' at offset 15`},
+		{name: "empty", expr: ``, wantErr: "expected field at offset 0"},
+	}
+	for _, tt := range errTests {
+		t.Run("with "+tt.name+" should error", func(t *testing.T) {
+			_, err := commits.Where(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("unexpected error:\n\twant: %s\n\tgot: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCmdWhere(t *testing.T) {
+	tr := newTestRepo(t)
+	tr.commit("feat(api): add thing", nil)
+	tr.commit("fix(deps): bump thing", map[string]string{"go.mod": "module thing"})
+	
+	got, err := renderTemplate(t, tr.dir, "{{ range .Commits.Where `Type in [\"feat\", \"fix\"] && Scope != \"deps\"` }}{{ .Subject }}{{ end }}")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := "feat(api): add thing"; got != want {
+		t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", want, got)
+	}
+	
+	// the files are only computed when the template references them
+	got, err = renderTemplate(t, tr.dir, `{{ range .Commits.Where "TotalAdditions > 0" }}{{ .Subject }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := "fix(deps): bump thing"; got != want {
+		t.Errorf("unexpected output:\n\twant: %s\n\tgot: %s", want, got)
+	}
+	
+	_, err = renderTemplate(t, tr.dir, `{{ range .Commits.Where "Type ==" }}{{ end }}`)
+	if err == nil || !strings.Contains(err.Error(), "invalid where expression") {
+		t.Errorf("unexpected error:\n\twant: invalid where expression\n\tgot: %v", err)
+	}
+}